@@ -1,58 +1,202 @@
 package scraper
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/mineroot/alert-data/scraper/region"
 )
 
+// ErrRegionNotFound is returned when AlertData holds no status of the requested region.
+var ErrRegionNotFound = errors.New("region not found")
+
 // Status represents the alert status for a region.
 type Status struct {
-	Region    region.ID
-	Enabled   bool
-	UpdatedAt time.Time
-	IsHistory bool // if this is true UpdatedAt may be inaccurate (zero)
+	Region    region.ID `json:"region"`
+	Level     Level     `json:"level"`
+	UpdatedAt time.Time `json:"updated_at"`
+	IsHistory bool      `json:"is_history"` // if this is true UpdatedAt may be inaccurate (zero)
+	Stale     bool      `json:"stale"`      // alert is enabled for longer than the stale expiry with no update, see WithStaleExpiry
+	Marker    rune      `json:"marker"`     // original emoji of the message: 🔴, 🟢 or 🟡; zero for seeded statuses
+	IsDrill   bool      `json:"is_drill"`   // parsed from an exercise message, consumers may filter it out
+
+	ThreatType      string `json:"threat_type,omitempty"`       // e.g. "ballistic", "uav" or "missile", see ParseAlertText; empty for clears or if not specified
+	SourceMessageID int64  `json:"source_message_id,omitempty"` // id of the message the status is parsed from; zero for seeded statuses
+}
+
+// Enabled reports whether the raid alert is active in the region, fully or partially.
+func (s Status) Enabled() bool {
+	return s.Level >= LevelPartial
+}
+
+// UpdatedAtIn returns UpdatedAt in loc for display, as statuses are stored in Europe/Kyiv timezone.
+// Panics if loc is nil.
+func (s Status) UpdatedAtIn(loc *time.Location) time.Time {
+	return s.UpdatedAt.In(loc)
+}
+
+// IsHeartbeat reports whether the status is a heartbeat sent on TgScraper.UpdatesChan(),
+// rather than a real update. See WithHeartbeat.
+func (s Status) IsHeartbeat() bool {
+	return s.Region == region.Invalid
 }
 
 // AlertData holds the raid status information for all regions.
 type AlertData struct {
-	lock *sync.RWMutex
-	data map[region.ID]*Status
+	lock        *sync.RWMutex
+	data        Store
+	seedRegions []region.ID
+	observers   map[chan struct{}]struct{}
+	subscribers map[*statusSubscriber]struct{}
+	changes     map[chan Status]struct{}
+	seed        map[region.ID]bool
+	events      []stateEvent            // sorted by at
+	previous    map[region.ID]*Status   // nil if only one status is stored after the seeded one
+	activeSince map[region.ID]time.Time // start of the ongoing alert of enabled regions
+	modifiedAt  time.Time               // when the stored state last changed, see ModifiedAt
+	now         func() time.Time
+
+	onTransition         func(oldStatus, newStatus Status)
+	eventSink            func(Event)
+	onKeepalive          func(Status)
+	equalTimestampPolicy EqualTimestampPolicy
+}
+
+// maxEvents is the max number of state transitions kept for AlertData.StateAt.
+const maxEvents = 10_000
+
+// stateEvent is a logged transition of the region's alert state.
+type stateEvent struct {
+	region  region.ID
+	enabled bool
+	at      time.Time
+}
+
+// newAlertData creates in-memory AlertData seeded with the given regions.
+// If seedRegions is empty, all regions are seeded.
+func newAlertData(seedRegions []region.ID) *AlertData {
+	return newAlertDataWithStore(seedRegions, newMemoryStore())
 }
 
-func newAlertData() *AlertData {
+// newAlertDataWithStore creates AlertData backed by the store and seeded with the given regions.
+// Regions that already exist in the store are not reseeded.
+func newAlertDataWithStore(seedRegions []region.ID, store Store) *AlertData {
+	if len(seedRegions) == 0 {
+		seedRegions = make([]region.ID, 0, region.Count())
+		for id, _ := range region.Iterator() {
+			seedRegions = append(seedRegions, id)
+		}
+	}
+
 	alertData := &AlertData{
-		lock: &sync.RWMutex{},
-		data: make(map[region.ID]*Status, region.Count()),
+		lock:        &sync.RWMutex{},
+		data:        store,
+		seedRegions: seedRegions,
+		observers:   make(map[chan struct{}]struct{}),
+		subscribers: make(map[*statusSubscriber]struct{}),
+		changes:     make(map[chan Status]struct{}),
+		previous:    make(map[region.ID]*Status),
+		activeSince: make(map[region.ID]time.Time),
+		now:         time.Now,
 	}
+	alertData.seedData(false)
+	alertData.modifiedAt = alertData.now()
+	return alertData
+}
 
-	// assume raid alert is disabled for all regions
-	for id, _ := range region.Iterator() {
-		alertData.set(&Status{
+// seedData stores the seeded statuses, overriding the existing ones if force is set,
+// and clears the logged transitions. Must be called with the write lock held.
+func (r *AlertData) seedData(force bool) {
+	// assume raid alert is disabled for all seeded regions
+	for _, id := range r.seedRegions {
+		r.data.CompareAndSet(Status{
 			Region:    id,
-			Enabled:   false,
+			Level:     LevelNone,
 			UpdatedAt: time.Time{},
 			IsHistory: true,
+		}, func(_ Status, exists bool) bool {
+			return force || !exists
 		})
 	}
 
-	// hardcode raid alerts in Crimea & Luhansk regions
-	// as it's long-running, and it's inefficient to parse Tg channel for last 2+ years
-	alertData.set(&Status{
-		Region:    region.Crimea,
-		Enabled:   true,
-		UpdatedAt: time.Date(2022, time.December, 11, 0, 22, 0, 0, kyivLocation),
-		IsHistory: true,
-	})
-	alertData.set(&Status{
-		Region:    region.Luhansk,
-		Enabled:   true,
-		UpdatedAt: time.Date(2022, time.April, 4, 19, 45, 0, 0, kyivLocation),
+	for _, status := range longRunningAlerts() {
+		r.data.CompareAndSet(*status, func(currentStatus Status, seeded bool) bool {
+			return seeded && (force || !status.UpdatedAt.Before(currentStatus.UpdatedAt))
+		})
+	}
+
+	// seeding is not logged as transitions
+	snapshot := r.data.Snapshot()
+	r.seed = make(map[region.ID]bool, len(snapshot))
+	clear(r.activeSince)
+	for id, status := range snapshot {
+		r.seed[id] = status.Enabled()
+		if status.Enabled() {
+			r.activeSince[id] = status.UpdatedAt
+		}
+	}
+	r.events = nil
+	clear(r.previous)
+}
+
+// Reset restores the initial seeded state: alerts are disabled in all seeded regions
+// except the long-running ones, e.g. to recover after a period of bad parsing.
+// Observers are notified of the reset, subscribers receive all reseeded statuses.
+func (r *AlertData) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.seedData(true)
+	for _, id := range r.seedRegions {
+		status, _ := r.data.Get(id)
+		r.publish(status)
+	}
+	r.notifyObservers()
+}
+
+// longRunningAlerts returns hardcoded raid alerts in Crimea & Luhansk regions
+// as it's long-running, and it's inefficient to parse Tg channel for last 2+ years
+func longRunningAlerts() []*Status {
+	return []*Status{
+		{
+			Region:    region.Crimea,
+			Level:     LevelFull,
+			UpdatedAt: time.Date(2022, time.December, 11, 0, 22, 0, 0, kyivLocation()),
+			IsHistory: true,
+		},
+		{
+			Region:    region.Luhansk,
+			Level:     LevelFull,
+			UpdatedAt: time.Date(2022, time.April, 4, 19, 45, 0, 0, kyivLocation()),
+			IsHistory: true,
+		},
+	}
+}
+
+// seededStatus returns the status the region is seeded with: the long-running alert or no alert.
+func seededStatus(id region.ID) Status {
+	for _, status := range longRunningAlerts() {
+		if status.Region == id {
+			return *status
+		}
+	}
+	return Status{
+		Region:    id,
+		Level:     LevelNone,
+		UpdatedAt: time.Time{},
 		IsHistory: true,
-	})
-	return alertData
+	}
+}
+
+// isLongRunningAlert reports whether the region has a hardcoded long-running alert, see longRunningAlerts.
+func isLongRunningAlert(id region.ID) bool {
+	return slices.ContainsFunc(longRunningAlerts(), func(s *Status) bool { return s.Region == id })
 }
 
 // GetByRegion retrieves the alert status for a specific region.
@@ -60,36 +204,485 @@ func newAlertData() *AlertData {
 func (r *AlertData) GetByRegion(id region.ID) (Status, error) {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
-	currentStatus, exists := r.data[id]
+	currentStatus, exists := r.data.Get(id)
 	if !exists {
-		return Status{}, fmt.Errorf("scraper: invalid region '%s'", id)
+		return Status{}, fmt.Errorf("scraper: invalid region '%s': %w", id, ErrRegionNotFound)
 	}
-	return *currentStatus, nil
+	return currentStatus, nil
+}
+
+// GetByCode returns the status of the region with the ISO 3166-2 code, see region.ParseCode.
+// Returns ErrRegionNotFound if the code is invalid or the region is not seeded.
+func (r *AlertData) GetByCode(code string) (Status, error) {
+	id := region.ParseCode(code)
+	if id == region.Invalid {
+		return Status{}, fmt.Errorf("scraper: invalid region code '%s': %w", code, ErrRegionNotFound)
+	}
+	return r.GetByRegion(id)
+}
+
+// GetByHashtag returns the status of the region with the hashtag, see region.ParseHashtag.
+// Returns ErrRegionNotFound if the hashtag is invalid or the region is not seeded.
+func (r *AlertData) GetByHashtag(tag string) (Status, error) {
+	id := region.ParseHashtag(tag)
+	if id == region.Invalid {
+		return Status{}, fmt.Errorf("scraper: invalid region hashtag '%s': %w", tag, ErrRegionNotFound)
+	}
+	return r.GetByRegion(id)
+}
+
+// GetByRegions retrieves the alert statuses for the regions in the requested order.
+// Returns an error if any region is invalid.
+func (r *AlertData) GetByRegions(ids ...region.ID) ([]Status, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	statuses := make([]Status, 0, len(ids))
+	for _, id := range ids {
+		currentStatus, exists := r.data.Get(id)
+		if !exists {
+			return nil, fmt.Errorf("scraper: invalid region '%s': %w", id, ErrRegionNotFound)
+		}
+		statuses = append(statuses, currentStatus)
+	}
+	return statuses, nil
 }
 
 // GetAll retrieves the alert statuses for all regions
 func (r *AlertData) GetAll() []Status {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
-	statuses := make([]Status, 0, len(r.data))
-	for _, status := range r.data {
-		statuses = append(statuses, *status)
+	return slices.Collect(maps.Values(r.data.Snapshot()))
+}
+
+// Observe returns a channel receiving a full snapshot of all statuses whenever any region changes.
+// Changes that happen while the receiver is busy are coalesced into a single snapshot.
+// The channel is closed when ctx is done.
+func (r *AlertData) Observe(ctx context.Context) <-chan map[region.ID]Status {
+	changed, unsubscribe := r.subscribe()
+
+	snapshots := make(chan map[region.ID]Status)
+	go func() {
+		defer close(snapshots)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case snapshots <- r.snapshot():
+			}
+		}
+	}()
+	return snapshots
+}
+
+// WaitForChange blocks until any region's status changes or ctx is done, waking all waiting callers.
+// It suits coarse refresh loops: wait, then take a snapshot, e.g. with ToSlice.
+func (r *AlertData) WaitForChange(ctx context.Context) error {
+	changed, unsubscribe := r.subscribe()
+	defer unsubscribe()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	}
+}
+
+// waitFor blocks until the status of the region satisfies cond or ctx is done.
+func (r *AlertData) waitFor(ctx context.Context, id region.ID, cond func(Status) bool) error {
+	changed, unsubscribe := r.subscribe()
+	defer unsubscribe()
+	for {
+		status, err := r.GetByRegion(id)
+		if err != nil {
+			return err
+		}
+		if cond(status) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// subscribe returns a channel signaled (coalesced) on every change and a func to unsubscribe.
+func (r *AlertData) subscribe() (<-chan struct{}, func()) {
+	changed := make(chan struct{}, 1)
+	r.lock.Lock()
+	r.observers[changed] = struct{}{}
+	r.lock.Unlock()
+
+	return changed, func() {
+		r.lock.Lock()
+		delete(r.observers, changed)
+		r.lock.Unlock()
 	}
+}
+
+func (r *AlertData) snapshot() map[region.ID]Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.data.Snapshot()
+}
+
+// ActiveSince returns when the ongoing alert of the region started.
+// Unlike UpdatedAt, it's kept while the alert is reconfirmed by repeated enabled messages, see WithKeepalive.
+// Returns false if the alert is disabled or the region is not seeded.
+func (r *AlertData) ActiveSince(id region.ID) (time.Time, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	activeSince, ok := r.activeSince[id]
+	return activeSince, ok
+}
+
+// LastTwo returns the previous and the current status of the region, e.g. to tell how long the alert lasted.
+// ok is false if the region is invalid or has no status stored after the seeded one.
+func (r *AlertData) LastTwo(id region.ID) (prev, curr Status, ok bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	previous := r.previous[id]
+	if previous == nil {
+		return Status{}, Status{}, false
+	}
+	curr, _ = r.data.Get(id)
+	return *previous, curr, true
+}
+
+// Equal reports whether both AlertData hold the same regions with equal alert levels and update times.
+func (r *AlertData) Equal(other *AlertData) bool {
+	if r == other {
+		return true
+	}
+	// snapshots are taken one by one to avoid lock ordering issues
+	snapshot, otherSnapshot := r.snapshot(), other.snapshot()
+	if len(snapshot) != len(otherSnapshot) {
+		return false
+	}
+	for id, status := range snapshot {
+		otherStatus, exists := otherSnapshot[id]
+		if !exists ||
+			status.Level != otherStatus.Level ||
+			!status.UpdatedAt.Equal(otherStatus.UpdatedAt) {
+			return false
+		}
+	}
+	return true
+}
+
+// markStale flags enabled statuses that haven't been updated for longer than expiry as Stale.
+// Long-running alerts are never flagged. Returns newly flagged statuses.
+func (r *AlertData) markStale(now time.Time, expiry time.Duration) []Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var stale []Status
+	for id, status := range r.data.Snapshot() {
+		if !status.Enabled() || status.Stale || now.Sub(status.UpdatedAt) <= expiry {
+			continue
+		}
+		if isLongRunningAlert(id) {
+			continue
+		}
+		original := status
+		status.Stale = true
+		_, _, stored := r.data.CompareAndSet(status, func(currentStatus Status, exists bool) bool {
+			return exists && sameStatus(currentStatus, original) // not updated meanwhile, e.g. by another replica
+		})
+		if !stored {
+			continue
+		}
+		r.publish(status)
+		stale = append(stale, status)
+	}
+	if len(stale) > 0 {
+		r.notifyObservers()
+	}
+	return stale
+}
+
+// ToSlice returns copies of all statuses sorted by region ID.
+// Unlike GetAll, the order is stable, e.g. for ranging in templates.
+func (r *AlertData) ToSlice() []Status {
+	return r.Filter(func(Status) bool {
+		return true
+	})
+}
+
+// Filter returns copies of the statuses matching pred, sorted by region ID.
+func (r *AlertData) Filter(pred func(Status) bool) []Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	statuses := make([]Status, 0)
+	for _, status := range r.data.Snapshot() {
+		if pred(status) {
+			statuses = append(statuses, status)
+		}
+	}
+	SortStatusesByRegion(statuses)
 	return statuses
 }
 
-func (r *AlertData) set(newStatus *Status) {
+// RecentlyCleared returns statuses of the regions whose alert was cleared within window before now, sorted by region ID.
+// Seeded statuses with unknown update time are ignored, as well as regions with a threat warning after the clear.
+func (r *AlertData) RecentlyCleared(window time.Duration, now time.Time) []Status {
+	return r.Filter(func(status Status) bool {
+		return status.Level == LevelNone &&
+			!status.UpdatedAt.IsZero() &&
+			!status.UpdatedAt.After(now) &&
+			now.Sub(status.UpdatedAt) <= window
+	})
+}
+
+// ClearedStatus is a cleared status along with the duration of the alert it ended, see AlertData.JustCleared.
+type ClearedStatus struct {
+	Status
+	Duration time.Duration `json:"duration"`
+}
+
+// JustCleared returns the regions whose alert ended within the window before now, sorted by region ID:
+// the current status is disabled and the previous one (see LastTwo), or the seeded one if none, is enabled.
+// Duration is measured from the logged start of the alert, or from the previous status if it's not logged.
+func (r *AlertData) JustCleared(window time.Duration, now time.Time) []ClearedStatus {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	cleared := make([]ClearedStatus, 0)
+	for id, status := range r.data.Snapshot() {
+		previous := r.previous[id]
+		if previous == nil {
+			seeded := seededStatus(id)
+			previous = &seeded
+		}
+		if status.Enabled() || !previous.Enabled() ||
+			status.UpdatedAt.After(now) || now.Sub(status.UpdatedAt) > window {
+			continue
+		}
+		startedAt := previous.UpdatedAt
+		for _, event := range slices.Backward(r.events) {
+			if event.region == id && event.enabled && !event.at.After(status.UpdatedAt) {
+				startedAt = event.at
+				break
+			}
+		}
+		cleared = append(cleared, ClearedStatus{Status: status, Duration: status.UpdatedAt.Sub(startedAt)})
+	}
+	slices.SortFunc(cleared, func(a, b ClearedStatus) int {
+		return compareStatusRegion(a.Status, b.Status)
+	})
+	return cleared
+}
+
+// RegionsByUpdatedAt returns up to limit statuses sorted by UpdatedAt in descending order, ties broken by region ID,
+// e.g. for a recent activity feed. Seeded statuses with zero UpdatedAt are skipped.
+// All statuses are returned if limit <= 0.
+func (r *AlertData) RegionsByUpdatedAt(limit int) []Status {
+	statuses := r.Filter(func(status Status) bool {
+		return !status.UpdatedAt.IsZero()
+	})
+	slices.SortStableFunc(statuses, func(a, b Status) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt) // statuses are sorted by region ID already
+	})
+	if limit <= 0 {
+		return statuses
+	}
+	return statuses[:min(limit, len(statuses))]
+}
+
+// LastUpdated returns the latest UpdatedAt among all statuses.
+func (r *AlertData) LastUpdated() time.Time {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var lastUpdated time.Time
+	for _, status := range r.data.Snapshot() {
+		if status.UpdatedAt.After(lastUpdated) {
+			lastUpdated = status.UpdatedAt
+		}
+	}
+	return lastUpdated
+}
+
+// ModifiedAt returns when the stored state last changed, e.g. for Last-Modified headers.
+// Unlike LastUpdated, it moves on every change, e.g. when a region is marked stale
+// or a status is replaced with an older one.
+func (r *AlertData) ModifiedAt() time.Time {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.modifiedAt
+}
+
+// lastModified returns ModifiedAt truncated to seconds for Last-Modified headers,
+// or zero if the state was modified within the current second, so it may change again unnoticed by If-Modified-Since.
+func (r *AlertData) lastModified() time.Time {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	lastModified := r.modifiedAt.UTC().Truncate(time.Second)
+	if !r.now().UTC().Truncate(time.Second).After(lastModified) {
+		return time.Time{}
+	}
+	return lastModified
+}
+
+// StateHash returns a stable hash of all statuses, e.g. for cache keys, ETags or cheap change polling.
+// Identical states hash identically regardless of map iteration order, any stored change changes the hash.
+// All fields are hashed as they're serialized to JSON, so the hash changes whenever ToSlice JSON does.
+// The statuses are read under the read lock.
+func (r *AlertData) StateHash() uint64 {
+	hash := fnv.New64a()
+	_ = json.NewEncoder(hash).Encode(r.ToSlice()) // writes to hash never fail
+	return hash.Sum64()
+}
+
+// StateAt reconstructs which regions were under alert at t by replaying logged transitions.
+// Regions with no transition logged before t have their seeded state.
+// Only the last 10 000 transitions are logged.
+func (r *AlertData) StateAt(t time.Time) map[region.ID]bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	state := maps.Clone(r.seed)
+	for _, event := range r.events {
+		if event.at.After(t) {
+			break
+		}
+		state[event.region] = event.enabled
+	}
+	return state
+}
+
+// logEvent must be called with the write lock held.
+func (r *AlertData) logEvent(event stateEvent) {
+	i, _ := slices.BinarySearchFunc(r.events, event.at, func(e stateEvent, at time.Time) int {
+		if e.at.After(at) {
+			return 1
+		}
+		return -1 // insert after events at the same time
+	})
+	r.events = slices.Insert(r.events, i, event)
+	if len(r.events) > maxEvents {
+		r.events = slices.Delete(r.events, 0, len(r.events)-maxEvents)
+	}
+}
+
+// set stores the status unless it's outdated.
+// Returns the previously stored status (zero if none) and whether the status was stored, as observed under the lock.
+func (r *AlertData) set(newStatus *Status) (Status, bool) {
 	if newStatus == nil {
+		return Status{}, false
+	}
+
+	oldStatus, stored := r.store(newStatus, false)
+	if !stored {
+		return Status{}, false
+	}
+	r.transition(oldStatus, *newStatus)
+	if r.onKeepalive != nil && oldStatus.Enabled() && oldStatus.Level == newStatus.Level {
+		r.onKeepalive(*newStatus)
+	}
+	return oldStatus, true
+}
+
+// revert replaces the stored status even if newStatus is older,
+// e.g. when the message the current status was parsed from is deleted.
+// Returns false if the stored status is the same already, so nothing is changed.
+func (r *AlertData) revert(newStatus Status) bool {
+	oldStatus, stored := r.store(&newStatus, true)
+	if stored {
+		r.transition(oldStatus, newStatus)
+	}
+	return stored
+}
+
+func (r *AlertData) transition(oldStatus, newStatus Status) {
+	if !isTransition(oldStatus, newStatus) {
 		return
 	}
+	if r.onTransition != nil {
+		r.onTransition(oldStatus, newStatus)
+	}
+	if r.eventSink != nil {
+		r.eventSink(newEvent(oldStatus, newStatus))
+	}
+}
 
+// store stores the status unless it's outdated or force is set.
+// The status is compared with the current one by the Store, so a newer status set by another replica isn't overwritten.
+// Returns the previously stored status (zero if none) and whether the status was stored.
+func (r *AlertData) store(newStatus *Status, force bool) (Status, bool) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
-	currentStatus, exists := r.data[newStatus.Region]
-	if exists && newStatus.UpdatedAt.Before(currentStatus.UpdatedAt) {
+	currentStatus, exists, stored := r.data.CompareAndSet(*newStatus, func(currentStatus Status, exists bool) bool {
+		return !exists || r.overwrites(currentStatus, *newStatus, force)
+	})
+	if !stored {
+		return Status{}, false
+	}
+	r.publish(*newStatus)
+	if !newStatus.Enabled() {
+		delete(r.activeSince, newStatus.Region)
+	} else if _, active := r.activeSince[newStatus.Region]; !active {
+		r.activeSince[newStatus.Region] = newStatus.UpdatedAt
+	}
+	if _, updated := r.previous[newStatus.Region]; updated {
+		r.previous[newStatus.Region] = &currentStatus
+	} else {
+		r.previous[newStatus.Region] = nil
+	}
+	if !exists || currentStatus.Enabled() != newStatus.Enabled() {
+		r.logEvent(stateEvent{
+			region:  newStatus.Region,
+			enabled: newStatus.Enabled(),
+			at:      newStatus.UpdatedAt,
+		})
+	}
+	r.notifyObservers()
+
+	return currentStatus, true
+}
+
+// overwrites reports whether newStatus replaces the stored currentStatus.
+func (r *AlertData) overwrites(currentStatus, newStatus Status, force bool) bool {
+	if force {
+		return !sameStatus(currentStatus, newStatus)
+	}
+	if newStatus.UpdatedAt.Before(currentStatus.UpdatedAt) {
 		// skip update if new status is older than current status
-		return
+		return false
+	}
+	if newStatus.UpdatedAt.Equal(currentStatus.UpdatedAt) && !r.equalTimestampPolicy.overwrites(currentStatus, newStatus) {
+		return false
+	}
+	// warning doesn't override an active alert
+	return newStatus.Level != LevelWarning || !currentStatus.Enabled()
+}
+
+// sameStatus reports whether the statuses are equal, comparing UpdatedAt regardless of the location.
+func sameStatus(a, b Status) bool {
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return false
+	}
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return a == b
+}
+
+// isTransition reports whether the alert level changed from oldStatus to newStatus.
+func isTransition(oldStatus, newStatus Status) bool {
+	return oldStatus.Level != newStatus.Level
+}
+
+// notifyObservers must be called with the write lock held after each change of the stored state.
+func (r *AlertData) notifyObservers() {
+	r.modifiedAt = r.now()
+	for changed := range r.observers {
+		select {
+		case changed <- struct{}{}:
+		default: // observer has a pending change already
+		}
 	}
-	r.data[newStatus.Region] = newStatus
 }
@@ -0,0 +1,672 @@
+package scraper_test
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestAlertData_Observe(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	alertData := scraper.NewAlertData(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots := alertData.Observe(ctx)
+
+	alertData.Set(scraper.Status{
+		Region:    region.Odesa,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-21 02:15:00"),
+	})
+
+	snapshot := <-snapshots
+	require.Len(t, snapshot, region.Count())
+	require.True(t, snapshot[region.Odesa].Enabled())
+	require.True(t, snapshot[region.Crimea].Enabled())
+	require.False(t, snapshot[region.KyivCity].Enabled())
+
+	// snapshot is a copy
+	snapshot[region.KyivCity] = scraper.Status{Level: scraper.LevelFull}
+	status, _ := alertData.GetByRegion(region.KyivCity)
+	require.False(t, status.Enabled())
+
+	// assert channel is closed on ctx cancel
+	cancel()
+	for range snapshots {
+	}
+}
+
+func TestAlertData_WaitForChange(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	alertData := scraper.NewAlertData(nil)
+
+	// all waiters are woken by a change
+	const waiters = 3
+	g, ctx := errgroup.WithContext(context.Background())
+	for range waiters {
+		g.Go(func() error {
+			return alertData.WaitForChange(ctx)
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		// keep changing until all waiters have subscribed and returned
+		updatedAt := strToDate("2024-08-21 02:15:00")
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				updatedAt = updatedAt.Add(time.Minute)
+				alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt})
+			}
+		}
+	}()
+	require.NoError(t, g.Wait())
+	close(done)
+
+	// waiter returns on ctx cancel
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, alertData.WaitForChange(ctx), context.DeadlineExceeded)
+}
+
+func TestAlertData_Filter(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	now := strToDate("2024-08-22 12:00:00")
+	for _, status := range []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: now.Add(-10 * time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: now.Add(-5 * time.Minute)},
+		{Region: region.Lviv, Level: scraper.LevelFull, UpdatedAt: now.Add(-2 * time.Hour)},
+		{Region: region.KyivCity, Level: scraper.LevelNone, UpdatedAt: now.Add(-1 * time.Minute)},
+	} {
+		alertData.Set(status)
+	}
+
+	statuses := alertData.Filter(func(status scraper.Status) bool {
+		return status.Enabled() && now.Sub(status.UpdatedAt) < time.Hour
+	})
+	require.Equal(t, []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: now.Add(-10 * time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: now.Add(-5 * time.Minute)},
+	}, statuses)
+
+	require.Empty(t, alertData.Filter(func(scraper.Status) bool { return false }))
+	require.Len(t, alertData.Filter(func(scraper.Status) bool { return true }), region.Count())
+}
+
+func TestAlertData_ToSlice(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+
+	statuses := alertData.ToSlice()
+	require.Len(t, statuses, region.Count())
+	for i := 1; i < len(statuses); i++ {
+		require.Less(t, statuses[i-1].Region, statuses[i].Region)
+	}
+}
+
+func TestAlertData_Warning(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+
+	// warning is stored for a clear region
+	warning := scraper.Status{Region: region.Kharkiv, Level: scraper.LevelWarning, UpdatedAt: strToDate("2024-08-22 12:30:00")}
+	alertData.Set(warning)
+	status, _ := alertData.GetByRegion(region.Kharkiv)
+	require.Equal(t, warning, status)
+
+	// full alert overrides the warning
+	alert := scraper.Status{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 12:35:00")}
+	alertData.Set(alert)
+	status, _ = alertData.GetByRegion(region.Kharkiv)
+	require.Equal(t, alert, status)
+
+	// newer warning doesn't override the active alert
+	alertData.Set(scraper.Status{Region: region.Kharkiv, Level: scraper.LevelWarning, UpdatedAt: strToDate("2024-08-22 12:40:00")})
+	status, _ = alertData.GetByRegion(region.Kharkiv)
+	require.Equal(t, alert, status)
+}
+
+func TestAlertData_LevelTransitions(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	var transitions [][2]scraper.Level
+	alertData.SetOnTransition(func(oldStatus, newStatus scraper.Status) {
+		transitions = append(transitions, [2]scraper.Level{oldStatus.Level, newStatus.Level})
+	})
+
+	updatedAt := strToDate("2024-08-22 12:00:00")
+	for _, level := range []scraper.Level{
+		scraper.LevelWarning,
+		scraper.LevelFull,
+		scraper.LevelPartial,
+		scraper.LevelPartial, // no transition
+		scraper.LevelNone,
+		scraper.LevelFull,
+		scraper.LevelNone,
+	} {
+		updatedAt = updatedAt.Add(time.Minute)
+		alertData.Set(scraper.Status{Region: region.Kharkiv, Level: level, UpdatedAt: updatedAt})
+		status, _ := alertData.GetByRegion(region.Kharkiv)
+		require.Equal(t, level, status.Level)
+	}
+	require.Equal(t, [][2]scraper.Level{
+		{scraper.LevelNone, scraper.LevelWarning},
+		{scraper.LevelWarning, scraper.LevelFull},
+		{scraper.LevelFull, scraper.LevelPartial},
+		{scraper.LevelPartial, scraper.LevelNone},
+		{scraper.LevelNone, scraper.LevelFull},
+		{scraper.LevelFull, scraper.LevelNone},
+	}, transitions)
+
+	// a warning doesn't override a partial alert
+	alertData.Set(scraper.Status{Region: region.Kharkiv, Level: scraper.LevelPartial, UpdatedAt: updatedAt.Add(time.Minute)})
+	alertData.Set(scraper.Status{Region: region.Kharkiv, Level: scraper.LevelWarning, UpdatedAt: updatedAt.Add(2 * time.Minute)})
+	status, _ := alertData.GetByRegion(region.Kharkiv)
+	require.Equal(t, scraper.LevelPartial, status.Level)
+}
+
+func TestStatus_Enabled(t *testing.T) {
+	for level, enabled := range map[scraper.Level]bool{
+		scraper.LevelNone:    false,
+		scraper.LevelWarning: false,
+		scraper.LevelPartial: true,
+		scraper.LevelFull:    true,
+	} {
+		require.Equal(t, enabled, scraper.Status{Level: level}.Enabled(), level.String())
+	}
+}
+
+func TestAlertData_RecentlyCleared(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	now := strToDate("2024-08-22 12:00:00")
+	for _, status := range []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: now.Add(-time.Hour)},
+		{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: now.Add(-5 * time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelNone, UpdatedAt: now.Add(-15 * time.Minute)},
+		{Region: region.Lviv, Level: scraper.LevelNone, UpdatedAt: now.Add(-2 * time.Hour)},
+		{Region: region.KyivCity, Level: scraper.LevelFull, UpdatedAt: now.Add(-1 * time.Minute)},
+		{Region: region.Sumy, Level: scraper.LevelNone, UpdatedAt: now.Add(-10 * time.Minute)},
+		{Region: region.Sumy, Level: scraper.LevelWarning, UpdatedAt: now.Add(-3 * time.Minute)},
+	} {
+		alertData.Set(status)
+	}
+
+	regions := func(statuses []scraper.Status) []region.ID {
+		ids := make([]region.ID, 0, len(statuses))
+		for _, status := range statuses {
+			ids = append(ids, status.Region)
+		}
+		return ids
+	}
+	require.Equal(t, []region.ID{region.Odesa}, regions(alertData.RecentlyCleared(10*time.Minute, now)))
+	require.Equal(t, []region.ID{region.Odesa, region.Kharkiv}, regions(alertData.RecentlyCleared(30*time.Minute, now)))
+	require.Equal(t, []region.ID{region.Lviv, region.Odesa, region.Kharkiv}, regions(alertData.RecentlyCleared(24*time.Hour, now)))
+	require.Empty(t, alertData.RecentlyCleared(time.Minute, now))
+}
+
+func TestAlertData_JustCleared(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	now := strToDate("2024-08-22 12:00:00")
+	for _, status := range []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: now.Add(-time.Hour)},
+		{Region: region.Odesa, Level: scraper.LevelPartial, UpdatedAt: now.Add(-30 * time.Minute)},
+		{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: now.Add(-5 * time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: now.Add(-40 * time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelNone, UpdatedAt: now.Add(-20 * time.Minute)},
+		{Region: region.Lviv, Level: scraper.LevelNone, UpdatedAt: now.Add(-time.Minute)}, // never enabled
+		{Region: region.KyivCity, Level: scraper.LevelFull, UpdatedAt: now.Add(-time.Minute)},
+	} {
+		alertData.Set(status)
+	}
+
+	cleared := alertData.JustCleared(10*time.Minute, now)
+	require.Len(t, cleared, 1)
+	require.Equal(t, region.Odesa, cleared[0].Region)
+	require.Equal(t, 55*time.Minute, cleared[0].Duration, "measured from the start of the alert")
+
+	cleared = alertData.JustCleared(30*time.Minute, now)
+	require.Len(t, cleared, 2)
+	require.Equal(t, region.Odesa, cleared[0].Region)
+	require.Equal(t, region.Kharkiv, cleared[1].Region)
+	require.Equal(t, 20*time.Minute, cleared[1].Duration)
+
+	require.Empty(t, alertData.JustCleared(time.Minute, now))
+
+	// the first update after seeding clears the long-running alert
+	alertData.Set(scraper.Status{Region: region.Crimea, Level: scraper.LevelNone, UpdatedAt: now.Add(-2 * time.Minute)})
+	cleared = alertData.JustCleared(10*time.Minute, now)
+	require.Len(t, cleared, 2)
+	require.Equal(t, region.Crimea, cleared[0].Region)
+	require.Equal(t, now.Add(-2*time.Minute).Sub(strToDate("2022-12-11 00:22:00")), cleared[0].Duration, "measured from the seeded status")
+	require.Equal(t, region.Odesa, cleared[1].Region)
+}
+
+func TestAlertData_ActiveSince(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	_, ok := alertData.ActiveSince(region.Odesa)
+	require.False(t, ok)
+	_, ok = alertData.ActiveSince(region.Crimea)
+	require.True(t, ok, "seeded long-running alert")
+
+	startedAt := strToDate("2024-08-22 10:00:00")
+	for _, status := range []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: startedAt},
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: startedAt.Add(30 * time.Minute)},
+		{Region: region.Odesa, Level: scraper.LevelPartial, UpdatedAt: startedAt.Add(time.Hour)},
+	} {
+		alertData.Set(status)
+		activeSince, ok := alertData.ActiveSince(region.Odesa)
+		require.True(t, ok)
+		require.Equal(t, startedAt, activeSince)
+	}
+
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: startedAt.Add(2 * time.Hour)})
+	_, ok = alertData.ActiveSince(region.Odesa)
+	require.False(t, ok)
+}
+
+func TestAlertData_RegionsByUpdatedAt(t *testing.T) {
+	alertData := scraper.NewAlertData([]region.ID{region.Odesa, region.Kharkiv, region.Lviv, region.Sumy, region.Kyiv})
+	updatedAt := strToDate("2024-08-22 10:00:00")
+	for _, status := range []scraper.Status{
+		{Region: region.Lviv, Level: scraper.LevelFull, UpdatedAt: updatedAt},
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt.Add(2 * time.Minute)},
+		{Region: region.Sumy, Level: scraper.LevelNone, UpdatedAt: updatedAt.Add(time.Minute)},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: updatedAt.Add(2 * time.Minute)},
+	} {
+		alertData.Set(status)
+	}
+
+	regions := func(statuses []scraper.Status) []region.ID {
+		ids := make([]region.ID, 0, len(statuses))
+		for _, status := range statuses {
+			ids = append(ids, status.Region)
+		}
+		return ids
+	}
+	require.Equal(t, []region.ID{region.Odesa, region.Kharkiv, region.Sumy, region.Lviv}, regions(alertData.RegionsByUpdatedAt(10)),
+		"seeded Kyiv oblast is skipped, ties are broken by region ID")
+	require.Equal(t, []region.ID{region.Odesa, region.Kharkiv}, regions(alertData.RegionsByUpdatedAt(2)))
+	require.Len(t, alertData.RegionsByUpdatedAt(0), 4, "no limit")
+	require.Len(t, alertData.RegionsByUpdatedAt(-1), 4, "no limit")
+}
+
+func TestAlertData_StateAt(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	for _, status := range []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 10:00:00")},
+		{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-22 11:00:00")},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 10:30:00")},
+	} {
+		alertData.Set(status)
+	}
+
+	state := alertData.StateAt(strToDate("2024-08-22 09:00:00"))
+	require.Len(t, state, region.Count())
+	require.False(t, state[region.Odesa])
+	require.False(t, state[region.Kharkiv])
+	require.True(t, state[region.Crimea]) // seeded
+
+	state = alertData.StateAt(strToDate("2024-08-22 10:45:00"))
+	require.True(t, state[region.Odesa])
+	require.True(t, state[region.Kharkiv])
+
+	state = alertData.StateAt(strToDate("2024-08-22 11:00:00"))
+	require.False(t, state[region.Odesa])
+	require.True(t, state[region.Kharkiv])
+}
+
+func TestStatus_UpdatedAtIn(t *testing.T) {
+	status := scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")}
+
+	utc := status.UpdatedAtIn(time.UTC)
+	require.Equal(t, time.UTC, utc.Location())
+	require.Equal(t, time.Date(2024, time.August, 20, 23, 15, 0, 0, time.UTC), utc)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	local := status.UpdatedAtIn(newYork)
+	require.Equal(t, "2024-08-20 19:15:00", local.Format(time.DateTime))
+	require.True(t, local.Equal(status.UpdatedAt))
+}
+
+func TestAlertData_GetByRegions(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+
+	statuses, err := alertData.GetByRegions(region.Luhansk, region.Odesa, region.Crimea)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	require.Equal(t, region.Luhansk, statuses[0].Region)
+	require.Equal(t, region.Odesa, statuses[1].Region)
+	require.Equal(t, region.Crimea, statuses[2].Region)
+
+	statuses, err = alertData.GetByRegions(region.Odesa, region.ID(420), region.Crimea)
+	require.ErrorContains(t, err, "invalid region 'region(420)'", "same format as GetByRegion")
+	require.ErrorIs(t, err, scraper.ErrRegionNotFound)
+	require.Nil(t, statuses)
+
+	statuses, err = alertData.GetByRegions()
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+}
+
+func TestAlertData_GetByCodeAndHashtag(t *testing.T) {
+	alertData := scraper.NewAlertData([]region.ID{region.Odesa, region.KyivCity})
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+
+	status, err := alertData.GetByCode("UA-51")
+	require.NoError(t, err)
+	require.Equal(t, region.Odesa, status.Region)
+	require.True(t, status.Enabled())
+
+	status, err = alertData.GetByHashtag("#м_Київ")
+	require.NoError(t, err)
+	require.Equal(t, region.KyivCity, status.Region)
+
+	for _, get := range []func() (scraper.Status, error){
+		func() (scraper.Status, error) { return alertData.GetByCode("UA-99") },
+		func() (scraper.Status, error) { return alertData.GetByCode("UA-46") }, // valid, but not seeded
+		func() (scraper.Status, error) { return alertData.GetByHashtag("#Курська_область") },
+		func() (scraper.Status, error) { return alertData.GetByHashtag("#Львівська_область") },
+	} {
+		_, err = get()
+		require.ErrorIs(t, err, scraper.ErrRegionNotFound)
+	}
+}
+
+func TestAlertData_NationalLevel(t *testing.T) {
+	var regions []region.ID
+	for id := range region.SortedIterator() {
+		if id != region.Crimea && id != region.Luhansk { // skip long-running alerts
+			regions = append(regions, id)
+		}
+	}
+	alertData := scraper.NewAlertData(nil)
+	require.Len(t, alertData.Filter(scraper.Status.Enabled), 2, "long-running alerts are seeded")
+	require.Equal(t, scraper.NationalCalm, alertData.NationalLevel(), "long-running alerts are not counted")
+
+	updatedAt := time.Now()
+	expected := map[int]scraper.NationalLevel{
+		1:  scraper.NationalElevated,
+		3:  scraper.NationalElevated,
+		4:  scraper.NationalHigh,
+		10: scraper.NationalHigh,
+		11: scraper.NationalCritical,
+	}
+	for i, id := range regions[:11] {
+		alertData.Set(scraper.Status{Region: id, Level: scraper.LevelFull, UpdatedAt: updatedAt})
+		if level, ok := expected[i+1]; ok {
+			require.Equal(t, level, alertData.NationalLevel(), "%d active", i+1)
+		}
+	}
+
+	custom := scraper.NationalThresholds{Elevated: 5, High: 20, Critical: 30}
+	require.Equal(t, scraper.NationalElevated, alertData.NationalLevelWith(custom))
+
+	for _, id := range regions[:11] {
+		alertData.Set(scraper.Status{Region: id, Level: scraper.LevelNone, UpdatedAt: updatedAt})
+	}
+	require.Equal(t, scraper.NationalCalm, alertData.NationalLevel())
+}
+
+func TestAlertData_Equal(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	require.True(t, alertData.Equal(alertData))
+
+	clone := scraper.NewAlertData(nil)
+	for _, status := range alertData.ToSlice() {
+		clone.Set(status)
+	}
+	require.True(t, alertData.Equal(clone))
+	require.True(t, clone.Equal(alertData))
+
+	clone.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 02:45:00")})
+	require.False(t, alertData.Equal(clone))
+	require.False(t, clone.Equal(alertData))
+
+	require.False(t, alertData.Equal(scraper.NewAlertData([]region.ID{region.Odesa})))
+}
+
+func TestAlertData_Store(t *testing.T) {
+	store := &fakeStore{data: make(map[region.ID]scraper.Status)}
+	alertData := scraper.NewAlertDataWithStore([]region.ID{region.Odesa, region.Kharkiv}, store)
+	require.Len(t, store.data, 2)
+	require.Equal(t, 2, store.sets)
+
+	status := scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")}
+	alertData.Set(status)
+	require.Equal(t, 3, store.sets)
+	require.Equal(t, status, store.data[region.Odesa])
+
+	gets := store.gets
+	store.data[region.Kharkiv] = scraper.Status{Region: region.Kharkiv, Level: scraper.LevelFull} // e.g. set by another replica
+	actual, err := alertData.GetByRegion(region.Kharkiv)
+	require.NoError(t, err)
+	require.True(t, actual.Enabled())
+	require.Equal(t, gets+1, store.gets)
+
+	// existing state is not reseeded
+	scraper.NewAlertDataWithStore([]region.ID{region.Odesa, region.Kharkiv}, store)
+	require.Equal(t, status, store.data[region.Odesa])
+}
+
+type fakeStore struct {
+	data       map[region.ID]scraper.Status
+	gets, sets int
+}
+
+func (s *fakeStore) Get(id region.ID) (scraper.Status, bool) {
+	s.gets++
+	status, exists := s.data[id]
+	return status, exists
+}
+
+func (s *fakeStore) CompareAndSet(status scraper.Status, cond func(scraper.Status, bool) bool) (scraper.Status, bool, bool) {
+	previous, exists := s.data[status.Region]
+	if !cond(previous, exists) {
+		return previous, exists, false
+	}
+	s.sets++
+	s.data[status.Region] = status
+	return previous, exists, true
+}
+
+func (s *fakeStore) Snapshot() map[region.ID]scraper.Status {
+	return maps.Clone(s.data)
+}
+
+func TestAlertData_SharedStore(t *testing.T) {
+	store := &lockedStore{data: make(map[region.ID]scraper.Status)}
+	replicas := []*scraper.AlertData{
+		scraper.NewAlertDataWithStore(nil, store),
+		scraper.NewAlertDataWithStore(nil, store),
+	}
+
+	updatedAt := strToDate("2024-08-21 02:15:00")
+	const statuses = 1000
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := i; j < statuses; j += len(replicas) {
+				level := scraper.LevelFull
+				if j%2 == 1 {
+					level = scraper.LevelNone
+				}
+				replica.Set(scraper.Status{Region: region.Odesa, Level: level, UpdatedAt: updatedAt.Add(time.Duration(j) * time.Minute)})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, replica := range replicas {
+		status, err := replica.GetByRegion(region.Odesa)
+		require.NoError(t, err)
+		require.Equal(t, updatedAt.Add((statuses-1)*time.Minute), status.UpdatedAt, "the newest status must win")
+	}
+}
+
+// lockedStore is a Store safe for concurrent use, shared by several AlertData.
+type lockedStore struct {
+	lock sync.Mutex
+	data map[region.ID]scraper.Status
+}
+
+func (s *lockedStore) Get(id region.ID) (scraper.Status, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status, exists := s.data[id]
+	return status, exists
+}
+
+func (s *lockedStore) CompareAndSet(status scraper.Status, cond func(scraper.Status, bool) bool) (scraper.Status, bool, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	previous, exists := s.data[status.Region]
+	if !cond(previous, exists) {
+		return previous, exists, false
+	}
+	s.data[status.Region] = status
+	return previous, exists, true
+}
+
+func (s *lockedStore) Snapshot() map[region.ID]scraper.Status {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return maps.Clone(s.data)
+}
+
+func TestAlertData_LastTwo(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	_, _, ok := alertData.LastTwo(region.Odesa)
+	require.False(t, ok)
+	_, _, ok = alertData.LastTwo(region.Crimea)
+	require.False(t, ok, "seeded long-running alert must not count")
+
+	enabled := scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")}
+	alertData.Set(enabled)
+	_, _, ok = alertData.LastTwo(region.Odesa)
+	require.False(t, ok)
+
+	disabled := scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 03:45:00")}
+	alertData.Set(disabled)
+	prev, curr, ok := alertData.LastTwo(region.Odesa)
+	require.True(t, ok)
+	require.Equal(t, enabled, prev)
+	require.Equal(t, disabled, curr)
+	require.Equal(t, 90*time.Minute, curr.UpdatedAt.Sub(prev.UpdatedAt))
+
+	// outdated status isn't stored
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 01:00:00")})
+	prev, curr, ok = alertData.LastTwo(region.Odesa)
+	require.True(t, ok)
+	require.Equal(t, enabled, prev)
+	require.Equal(t, disabled, curr)
+}
+
+func TestAlertData_Reset(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	seeded := alertData.ToSlice()
+
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	alertData.Set(scraper.Status{Region: region.Crimea, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	require.NotEqual(t, seeded, alertData.ToSlice())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots := alertData.Observe(ctx)
+
+	alertData.Reset()
+	require.Equal(t, seeded, alertData.ToSlice())
+	status, _ := alertData.GetByRegion(region.Crimea)
+	require.True(t, status.Enabled(), "long-running alert must be restored")
+	_, _, ok := alertData.LastTwo(region.Odesa)
+	require.False(t, ok)
+
+	snapshot := <-snapshots
+	require.False(t, snapshot[region.Odesa].Enabled())
+}
+
+func TestAlertData_EqualTimestampPolicy(t *testing.T) {
+	updatedAt := strToDate("2024-08-22 12:00:00")
+	live := scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt}
+	history := scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: updatedAt, IsHistory: true}
+
+	tests := []struct {
+		policy       scraper.EqualTimestampPolicy
+		liveFirst    scraper.Status // stored after live, then history arrive
+		historyFirst scraper.Status // stored after history, then live arrive
+		liveThenLive scraper.Level  // stored after two live statuses
+	}{
+		{scraper.PreferLive, live, live, scraper.LevelPartial},
+		{scraper.KeepExisting, live, history, scraper.LevelFull},
+		{scraper.Overwrite, history, live, scraper.LevelPartial},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			alertData := scraper.NewAlertData(nil)
+			alertData.SetEqualTimestampPolicy(test.policy)
+			alertData.Set(live)
+			alertData.Set(history)
+			status, _ := alertData.GetByRegion(region.Odesa)
+			require.Equal(t, test.liveFirst, status)
+
+			alertData = scraper.NewAlertData(nil)
+			alertData.SetEqualTimestampPolicy(test.policy)
+			alertData.Set(history)
+			alertData.Set(live)
+			status, _ = alertData.GetByRegion(region.Odesa)
+			require.Equal(t, test.historyFirst, status)
+
+			alertData = scraper.NewAlertData(nil)
+			alertData.SetEqualTimestampPolicy(test.policy)
+			alertData.Set(live)
+			alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelPartial, UpdatedAt: updatedAt})
+			status, _ = alertData.GetByRegion(region.Odesa)
+			require.Equal(t, test.liveThenLive, status.Level)
+		})
+	}
+}
+
+func TestAlertData_StateHash(t *testing.T) {
+	updatedAt := strToDate("2024-08-22 10:00:00")
+	newAlertData := func() *scraper.AlertData {
+		alertData := scraper.NewAlertData(nil)
+		alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt})
+		alertData.Set(scraper.Status{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: updatedAt})
+		return alertData
+	}
+
+	alertData, clone := newAlertData(), newAlertData()
+	hash := alertData.StateHash()
+	for range 10 {
+		require.Equal(t, hash, alertData.StateHash())
+	}
+	require.Equal(t, hash, clone.StateHash())
+
+	clone.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: updatedAt.Add(time.Minute)})
+	require.NotEqual(t, hash, clone.StateHash())
+	clone.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt.Add(2 * time.Minute)})
+	require.NotEqual(t, hash, clone.StateHash(), "updated time is hashed")
+
+	// e.g. a live status replacing the history one of the same minute, see PreferLive
+	clone.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt.Add(3 * time.Minute), IsHistory: true})
+	hash = clone.StateHash()
+	clone.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt.Add(3 * time.Minute)})
+	require.NotEqual(t, hash, clone.StateHash(), "all serialized fields are hashed")
+}
@@ -0,0 +1,73 @@
+package scraper
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// maxAppliedMessagesPerRegion is the max number of statuses remembered per region to re-derive its state.
+const maxAppliedMessagesPerRegion = 32
+
+// appliedMessages tracks which message produced which status, to revert the state if the message is deleted.
+type appliedMessages struct {
+	lock     sync.Mutex
+	byRegion map[region.ID][]appliedStatus // sorted by status.UpdatedAt
+}
+
+type appliedStatus struct {
+	messageId int64
+	status    Status
+}
+
+func newAppliedMessages() *appliedMessages {
+	return &appliedMessages{
+		byRegion: make(map[region.ID][]appliedStatus),
+	}
+}
+
+// add remembers the status parsed from the message.
+func (r *appliedMessages) add(messageId int64, status Status) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	applied := r.byRegion[status.Region]
+	i, _ := slices.BinarySearchFunc(applied, status, func(a appliedStatus, s Status) int {
+		if a.status.UpdatedAt.After(s.UpdatedAt) {
+			return 1
+		}
+		return -1 // insert after statuses at the same time
+	})
+	applied = slices.Insert(applied, i, appliedStatus{messageId: messageId, status: status})
+	if len(applied) > maxAppliedMessagesPerRegion {
+		applied = slices.Delete(applied, 0, len(applied)-maxAppliedMessagesPerRegion)
+	}
+	r.byRegion[status.Region] = applied
+}
+
+// remove forgets statuses of the deleted messages.
+// Returns the latest remaining status of each affected region,
+// or the seeded default status if nothing remains for the region.
+func (r *appliedMessages) remove(messageIds []int64) []Status {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var rederived []Status
+	for id, applied := range r.byRegion {
+		remaining := slices.DeleteFunc(slices.Clone(applied), func(a appliedStatus) bool {
+			return slices.Contains(messageIds, a.messageId)
+		})
+		if len(remaining) == len(applied) {
+			continue
+		}
+		r.byRegion[id] = remaining
+		if len(remaining) == 0 {
+			rederived = append(rederived, seededStatus(id))
+			continue
+		}
+		rederived = append(rederived, remaining[len(remaining)-1].status)
+	}
+	slices.SortFunc(rederived, func(a, b Status) int {
+		return region.CompareID(a.Region, b.Region)
+	})
+	return rederived
+}
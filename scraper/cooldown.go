@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// regionCooldown limits emitted updates to one per region per period.
+// Updates during the cooldown are held back, and the last of them is released when the cooldown ends
+// if it differs from the emitted one.
+type regionCooldown struct {
+	period  time.Duration
+	regions map[region.ID]*cooldownState
+}
+
+type cooldownState struct {
+	until   time.Time
+	emitted Status
+	pending *Status
+}
+
+func newRegionCooldown(period time.Duration) *regionCooldown {
+	return &regionCooldown{
+		period:  period,
+		regions: make(map[region.ID]*cooldownState),
+	}
+}
+
+// allow reports whether the status can be emitted now, otherwise it's held back until the cooldown ends.
+func (r *regionCooldown) allow(status Status, now time.Time) bool {
+	if r.period <= 0 {
+		return true
+	}
+	state, exists := r.regions[status.Region]
+	if exists && now.Before(state.until) {
+		state.pending = &status
+		return false
+	}
+	r.regions[status.Region] = &cooldownState{
+		until:   now.Add(r.period),
+		emitted: status,
+	}
+	return true
+}
+
+// due returns held back statuses whose cooldown has ended by now and which differ from the emitted one,
+// e.g. by ThreatType while the Level is the same.
+func (r *regionCooldown) due(now time.Time) []Status {
+	var statuses []Status
+	for _, state := range r.regions {
+		if state.pending == nil || now.Before(state.until) {
+			continue
+		}
+		pending := *state.pending
+		state.pending = nil
+		if sameStatus(pending, state.emitted) {
+			continue
+		}
+		state.emitted = pending
+		state.until = now.Add(r.period)
+		statuses = append(statuses, pending)
+	}
+	return statuses
+}
+
+// nextDeadline returns the earliest end of cooldown among regions with held back statuses.
+func (r *regionCooldown) nextDeadline() (time.Time, bool) {
+	var deadline time.Time
+	for _, state := range r.regions {
+		if state.pending != nil && (deadline.IsZero() || state.until.Before(deadline)) {
+			deadline = state.until
+		}
+	}
+	return deadline, !deadline.IsZero()
+}
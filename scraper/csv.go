@@ -0,0 +1,40 @@
+package scraper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"region_id", "region_name", "enabled", "updated_at"}
+
+// WriteCSV writes current statuses to w as CSV sorted by region ID, with a header row:
+// region_id, region_name, enabled, updated_at (RFC 3339 in Europe/Kyiv, empty if unknown).
+func (r *AlertData) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("scraper: unable to write csv: %w", err)
+	}
+	for _, status := range r.ToSlice() {
+		var updatedAt string
+		if !status.UpdatedAt.IsZero() {
+			updatedAt = status.UpdatedAt.In(kyivLocation()).Format(time.RFC3339)
+		}
+		record := []string{
+			strconv.Itoa(int(status.Region)),
+			status.Region.String(),
+			strconv.FormatBool(status.Enabled()),
+			updatedAt,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("scraper: unable to write csv: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("scraper: unable to write csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package scraper_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestAlertData_WriteCSV(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+
+	var buf bytes.Buffer
+	require.NoError(t, alertData.WriteCSV(&buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"region_id", "region_name", "enabled", "updated_at"}, records[0])
+	require.Len(t, records, region.Count()+1)
+
+	for i, record := range records[1:] {
+		id, err := strconv.Atoi(record[0])
+		require.NoError(t, err)
+		if i > 0 {
+			previousId, _ := strconv.Atoi(records[i][0])
+			require.Less(t, previousId, id, "records must be sorted by region id")
+		}
+		status, err := alertData.GetByRegion(region.ID(id))
+		require.NoError(t, err)
+		require.Equal(t, status.Region.String(), record[1])
+		require.Equal(t, strconv.FormatBool(status.Enabled()), record[2])
+	}
+
+	i := slices.IndexFunc(records, func(record []string) bool {
+		return record[0] == strconv.Itoa(int(region.Odesa))
+	})
+	require.Equal(t, []string{"true", "2024-08-21T02:15:00+03:00"}, records[i][2:])
+	i = slices.IndexFunc(records, func(record []string) bool {
+		return record[0] == strconv.Itoa(int(region.KyivCity))
+	})
+	require.Equal(t, []string{"false", ""}, records[i][2:], "seeded status has no update time")
+}
@@ -0,0 +1,40 @@
+package scraper
+
+// EqualTimestampPolicy decides whether a status replaces the stored status of the region with the same UpdatedAt.
+// Statuses are parsed with minute precision, so e.g. a history message and a real-time update
+// may have equal timestamps while arriving in any order.
+type EqualTimestampPolicy int
+
+const (
+	// PreferLive replaces the stored status unless it's a real-time one and the new status is from history.
+	PreferLive EqualTimestampPolicy = iota
+	// KeepExisting never replaces the stored status with an equal timestamp, the first arrived status wins.
+	KeepExisting
+	// Overwrite always replaces the stored status with an equal timestamp, the last arrived status wins.
+	Overwrite
+)
+
+func (p EqualTimestampPolicy) String() string {
+	switch p {
+	case PreferLive:
+		return "prefer live"
+	case KeepExisting:
+		return "keep existing"
+	case Overwrite:
+		return "overwrite"
+	default:
+		return "unknown"
+	}
+}
+
+// overwrites reports whether newStatus replaces currentStatus with the same UpdatedAt.
+func (p EqualTimestampPolicy) overwrites(currentStatus, newStatus Status) bool {
+	switch p {
+	case KeepExisting:
+		return false
+	case Overwrite:
+		return true
+	default:
+		return !newStatus.IsHistory || currentStatus.IsHistory
+	}
+}
@@ -0,0 +1,26 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// Event is a transition of the region's alert level, see WithEventSink.
+type Event struct {
+	Region    region.ID `json:"region"`
+	From      Level     `json:"from"`
+	To        Level     `json:"to"`
+	At        time.Time `json:"at"`         // UpdatedAt of the new status
+	MessageID int64     `json:"message_id"` // SourceMessageID of the new status; zero if unknown
+}
+
+func newEvent(oldStatus, newStatus Status) Event {
+	return Event{
+		Region:    newStatus.Region,
+		From:      oldStatus.Level,
+		To:        newStatus.Level,
+		At:        newStatus.UpdatedAt,
+		MessageID: newStatus.SourceMessageID,
+	}
+}
@@ -0,0 +1,49 @@
+package scraper
+
+import (
+	"context"
+	"time"
+)
+
+// NewAlertData exposes newAlertData for tests in scraper_test package.
+var NewAlertData = newAlertData
+
+// NewAlertDataWithStore exposes newAlertDataWithStore for tests in scraper_test package.
+var NewAlertDataWithStore = newAlertDataWithStore
+
+// Set exposes set for tests in scraper_test package.
+func (r *AlertData) Set(status Status) {
+	r.set(&status)
+}
+
+// SetOnTransition exposes onTransition for tests in scraper_test package.
+func (r *AlertData) SetOnTransition(onTransition func(oldStatus, newStatus Status)) {
+	r.onTransition = onTransition
+}
+
+// SetEqualTimestampPolicy exposes equalTimestampPolicy for tests in scraper_test package.
+func (r *AlertData) SetEqualTimestampPolicy(policy EqualTimestampPolicy) {
+	r.equalTimestampPolicy = policy
+}
+
+// SetNow replaces the clock of AlertData for tests in scraper_test package.
+func (r *AlertData) SetNow(now func() time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.now = now
+}
+
+// SendUpdate exposes sendUpdate for tests in scraper_test package.
+func (r *TgScraper) SendUpdate(ctx context.Context, status Status) {
+	r.sendUpdate(ctx, status)
+}
+
+// ParseSummaryMessage exposes parseSummaryMessage for tests in scraper_test package.
+var ParseSummaryMessage = parseSummaryMessage
+
+// SubscribersCount returns the number of subscribers for tests in scraper_test package.
+func (r *AlertData) SubscribersCount() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.subscribers)
+}
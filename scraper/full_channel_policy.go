@@ -0,0 +1,26 @@
+package scraper
+
+// FullChannelPolicy decides what happens to a status update when UpdatesChan() is full, i.e. the consumer is slow.
+type FullChannelPolicy int
+
+const (
+	// Block waits until the update is received, or until the update discard timeout expires, see WithUpdateDiscardTimeout.
+	Block FullChannelPolicy = iota
+	// DropNewest drops the update immediately.
+	DropNewest
+	// DropOldest evicts the buffered update to make room for the new one, so the consumer gets the freshest state.
+	DropOldest
+)
+
+func (p FullChannelPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop newest"
+	case DropOldest:
+		return "drop oldest"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,210 @@
+// Package graphql serves scraper.AlertData over a minimal GraphQL endpoint:
+//
+//	type Query {
+//	  alerts: [Alert!]!           # all regions sorted by region ID
+//	  alert(region: ID!): Alert   # region ID or name, null if not found
+//	  activeRegions: [Alert!]!    # regions with enabled alert
+//	}
+//
+//	type Alert {
+//	  region: Int!
+//	  name: String!
+//	  enabled: Boolean!
+//	  warning: Boolean!
+//	  level: String!              # none, warning, partial or full
+//	  updatedAt: String           # RFC 3339, null if unknown
+//	  isHistory: Boolean!
+//	  stale: Boolean!
+//	}
+//
+// Only queries without variables, fragments and directives are supported.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// NewHandler creates an HTTP handler resolving GraphQL queries over the alert data.
+// The query is read from the JSON body of POST requests, or from the "query" parameter of GET requests.
+func NewHandler(alertData *scraper.AlertData) http.Handler {
+	return &handler{alertData: alertData}
+}
+
+type handler struct {
+	alertData *scraper.AlertData
+}
+
+type request struct {
+	Query string `json:"query"`
+}
+
+type response struct {
+	Data   any             `json:"data,omitempty"`
+	Errors []responseError `json:"errors,omitempty"`
+}
+
+type responseError struct {
+	Message string `json:"message"`
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, response{Errors: []responseError{{"invalid request body"}}})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	selections, err := parseQuery(req.Query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, response{Errors: []responseError{{"syntax error: " + err.Error()}}})
+		return
+	}
+	data, err := h.resolveQuery(selections)
+	if err != nil {
+		writeJSON(w, http.StatusOK, response{Errors: []responseError{{err.Error()}}})
+		return
+	}
+	writeJSON(w, http.StatusOK, response{Data: data})
+}
+
+func (h *handler) resolveQuery(selections []field) (object, error) {
+	result := make(object, 0, len(selections))
+	for _, f := range selections {
+		var value any
+		var err error
+		switch f.name {
+		case "__typename":
+			value = "Query"
+		case "alerts":
+			value, err = resolveAlerts(f, h.alertData.ToSlice())
+		case "activeRegions":
+			value, err = resolveAlerts(f, h.alertData.Filter(func(status scraper.Status) bool {
+				return status.Enabled()
+			}))
+		case "alert":
+			value, err = h.resolveAlert(f)
+		default:
+			err = fmt.Errorf("cannot query field %q on type \"Query\"", f.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, member{key: f.key(), value: value})
+	}
+	return result, nil
+}
+
+func (h *handler) resolveAlert(f field) (any, error) {
+	var id region.ID
+	switch arg := f.args["region"].(type) {
+	case int:
+		id = region.ParseId(arg)
+	case string:
+		id = region.ParseNameOrId(arg)
+	default:
+		return nil, fmt.Errorf("field \"alert\" argument \"region\" of type \"ID!\" is required")
+	}
+	status, err := h.alertData.GetByRegion(id)
+	if err != nil {
+		return nil, nil // not found
+	}
+	return resolveStatus(f, status)
+}
+
+func resolveAlerts(f field, statuses []scraper.Status) ([]object, error) {
+	alerts := make([]object, 0, len(statuses))
+	for _, status := range statuses {
+		alert, err := resolveStatus(f, status)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func resolveStatus(f field, status scraper.Status) (object, error) {
+	if len(f.selections) == 0 {
+		return nil, fmt.Errorf("field %q of type \"Alert\" must have a selection of subfields", f.name)
+	}
+	alert := make(object, 0, len(f.selections))
+	for _, sf := range f.selections {
+		var value any
+		switch sf.name {
+		case "__typename":
+			value = "Alert"
+		case "region":
+			value = int(status.Region)
+		case "name":
+			value = status.Region.String()
+		case "enabled":
+			value = status.Enabled()
+		case "warning":
+			value = status.Level == scraper.LevelWarning
+		case "level":
+			value = status.Level.String()
+		case "updatedAt":
+			if !status.UpdatedAt.IsZero() {
+				value = status.UpdatedAt.Format(time.RFC3339)
+			}
+		case "isHistory":
+			value = status.IsHistory
+		case "stale":
+			value = status.Stale
+		default:
+			return nil, fmt.Errorf("cannot query field %q on type \"Alert\"", sf.name)
+		}
+		alert = append(alert, member{key: sf.key(), value: value})
+	}
+	return alert, nil
+}
+
+// object is a JSON object keeping the order of the selected fields.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
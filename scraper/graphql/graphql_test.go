@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/graphql"
+)
+
+func TestHandler(t *testing.T) {
+	// seeded alert data, only long-running alerts in Crimea and Luhansk are enabled
+	handler := graphql.NewHandler(scraper.NewTgScraper(nil).AlertData())
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"query": "query Active { activeRegions { region name, enabled } odesa: alert(region: 15) { name enabled updatedAt } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"data": {
+		"activeRegions": [
+			{"region": 1, "name": "Автономна Республіка Крим", "enabled": true},
+			{"region": 12, "name": "Луганська область", "enabled": true}
+		],
+		"odesa": {"name": "Одеська область", "enabled": false, "updatedAt": null}
+	}}`, rec.Body.String())
+	require.True(t, strings.HasPrefix(rec.Body.String(), `{"data":{"activeRegions":[{"region":1,"name"`), "fields must keep the query order")
+
+	rec = post(`{"query": "{ alert(region: \"Луганська область\") { updatedAt } missing: alert(region: 42) { enabled } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"data": {"alert": {"updatedAt": "2022-04-04T19:45:00+03:00"}, "missing": null}}`, rec.Body.String())
+
+	// string IDs are numeric ids or names
+	rec = post(`{"query": "{ byId: alert(region: \"15\") { name } byName: alert(region: \"Одеська область\") { name } unknown: alert(region: \"42\") { name } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"data": {"byId": {"name": "Одеська область"}, "byName": {"name": "Одеська область"}, "unknown": null}}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ alerts { region } }"), nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 27, strings.Count(rec.Body.String(), `"region"`))
+
+	rec = post(`{"query": "{ alerts { color } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"errors": [{"message": "cannot query field \"color\" on type \"Alert\""}]}`, rec.Body.String())
+
+	rec = post(`{"query": "{ alerts { region }"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "syntax error")
+}
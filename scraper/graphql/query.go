@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// field is a parsed field selection, e.g. `alert(region: 15) { enabled }`.
+type field struct {
+	alias      string
+	name       string
+	args       map[string]any // int or string values
+	selections []field
+}
+
+// key returns the response key of the field.
+func (f field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// parseQuery parses a query document with a single anonymous or named query operation.
+// Variables, fragments and directives aren't supported.
+func parseQuery(query string) ([]field, error) {
+	p := &parser{tokens: tokenize(query)}
+	if p.peek() == "query" {
+		p.next()
+		if p.peek() != "{" {
+			p.next() // operation name
+		}
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "" {
+		return nil, fmt.Errorf("unexpected %q after the query", p.peek())
+	}
+	return selections, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *parser) expect(token string) error {
+	if actual := p.next(); actual != token {
+		return fmt.Errorf("expected %q, got %q", token, actual)
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []field
+	for p.peek() != "}" {
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, f)
+	}
+	p.next()
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return selections, nil
+}
+
+func (p *parser) field() (field, error) {
+	var f field
+	name, err := p.name()
+	if err != nil {
+		return f, err
+	}
+	f.name = name
+	if p.peek() == ":" {
+		p.next()
+		f.alias = name
+		if f.name, err = p.name(); err != nil {
+			return f, err
+		}
+	}
+	if p.peek() == "(" {
+		if f.args, err = p.arguments(); err != nil {
+			return f, err
+		}
+	}
+	if p.peek() == "{" {
+		if f.selections, err = p.selectionSet(); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) arguments() (map[string]any, error) {
+	p.next() // (
+	args := make(map[string]any)
+	for p.peek() != ")" {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		value := p.next()
+		switch {
+		case strings.HasPrefix(value, `"`):
+			if args[name], err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("invalid string %s", value)
+			}
+		default:
+			if args[name], err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid value %q of argument %q", value, name)
+			}
+		}
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *parser) name() (string, error) {
+	token := p.next()
+	if token == "" || !isNameStart(rune(token[0])) {
+		return "", fmt.Errorf("expected name, got %q", token)
+	}
+	return token, nil
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// tokenize splits the query into punctuators, names, numbers and quoted strings.
+// Commas are insignificant in GraphQL, so they're skipped along with whitespace and comments.
+func tokenize(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("{}():", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(query) && query[j] != '"' {
+				if query[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(query))
+			tokens = append(tokens, query[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(query) && strings.IndexByte(" \t\n\r,#{}():\"", query[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
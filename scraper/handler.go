@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// NewHandler creates an HTTP handler serving the alert data as JSON:
+//
+//	GET /alerts       all statuses sorted by region ID, supports conditional requests
+//	GET /alerts/{id}  status of the region
+//	GET /alerts/stream  server-sent events: a snapshot of all statuses, then each stored status
+//
+// Errors are returned as JSON too, e.g. {"error":"invalid region"}.
+func NewHandler(alertData *AlertData) http.Handler {
+	h := &handler{alertData: alertData}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /alerts", h.alerts)
+	mux.HandleFunc("GET /alerts/stream", h.stream)
+	mux.HandleFunc("GET /alerts/{id}", h.alert)
+	return mux
+}
+
+// ssePingInterval is how often a ping comment is sent to keep an idle event stream alive.
+const ssePingInterval = 15 * time.Second
+
+type handler struct {
+	alertData *AlertData
+}
+
+func (h *handler) alerts(w http.ResponseWriter, r *http.Request) {
+	etag := fmt.Sprintf(`"%x"`, h.alertData.StateHash())
+	lastModified := h.alertData.lastModified()
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.alertData.ToSlice())
+}
+
+func (h *handler) alert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid region")
+		return
+	}
+	status, err := h.alertData.GetByRegion(region.ID(id))
+	if errors.Is(err, ErrRegionNotFound) {
+		writeError(w, http.StatusNotFound, "region not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// stream sends a "snapshot" event with all statuses sorted by region ID, then a "status" event for each stored status,
+// so only changed regions are sent. Event ids are the state hash, see AlertData.StateHash.
+// A ping comment is sent every ssePingInterval. The subscription is released when the client disconnects.
+func (h *handler) stream(w http.ResponseWriter, r *http.Request) {
+	snapshot, updates, cancel := h.alertData.SubscribeWithSnapshot()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	statuses := slices.Collect(maps.Values(snapshot))
+	SortStatusesByRegion(statuses)
+	if writeEvent(w, "snapshot", h.alertData.StateHash(), statuses) != nil || rc.Flush() != nil {
+		return
+	}
+
+	ping := time.NewTicker(ssePingInterval)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case status := <-updates:
+			err = writeEvent(w, "status", h.alertData.StateHash(), status)
+		case <-ping.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		}
+		if err != nil || rc.Flush() != nil {
+			return // client disconnected
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, stateHash uint64, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\nid: %x\ndata: %s\n\n", event, stateHash, data)
+	return err
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since if the former is absent.
+// ETag is the strong validator: Last-Modified has a one-second resolution, so it's zero (not sent, and
+// If-Modified-Since is ignored) while the state was changed within the current second.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return ifNoneMatch == etag || ifNoneMatch == "*"
+	}
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
+// errorResponse is the JSON body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,200 @@
+package scraper_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestHandler_Alerts(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	now := strToDate("2024-08-21 02:16:00")
+	alertData.SetNow(func() time.Time {
+		return now
+	})
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	handler := scraper.NewHandler(alertData)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	now = now.Add(time.Minute)
+	rec := get(nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var statuses []scraper.Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	require.Len(t, statuses, region.Count())
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	lastModified := rec.Header().Get("Last-Modified")
+	require.Equal(t, "Tue, 20 Aug 2024 23:16:00 GMT", lastModified, "last change of the state")
+
+	// unchanged state
+	rec = get(http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.Bytes())
+	rec = get(http.Header{"If-Modified-Since": {lastModified}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+
+	// changed state, the latest UpdatedAt stays the same
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	now = now.Add(time.Minute)
+	rec = get(http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEqual(t, etag, rec.Header().Get("ETag"))
+	rec = get(http.Header{"If-Modified-Since": {lastModified}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "Tue, 20 Aug 2024 23:17:00 GMT", rec.Header().Get("Last-Modified"))
+}
+
+func TestHandler_AlertsSameSecond(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	now := strToDate("2024-08-21 02:16:00").Add(100 * time.Millisecond)
+	alertData.SetNow(func() time.Time {
+		return now
+	})
+	handler := scraper.NewHandler(alertData)
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")})
+	now = now.Add(100 * time.Millisecond)
+	rec := get(nil)
+	require.Empty(t, rec.Header().Get("Last-Modified"), "the state may change again within the same second")
+
+	// the second change within the same second
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 02:16:00")})
+	now = now.Add(100 * time.Millisecond)
+	rec = get(http.Header{"If-Modified-Since": {"Tue, 20 Aug 2024 23:16:00 GMT"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	var statuses []scraper.Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	i := slices.IndexFunc(statuses, func(status scraper.Status) bool {
+		return status.Region == region.Odesa
+	})
+	require.NotEqual(t, -1, i)
+	require.Equal(t, scraper.LevelNone, statuses[i].Level)
+	require.True(t, strToDate("2024-08-21 02:16:00").Equal(statuses[i].UpdatedAt), "the latest change must be served")
+
+	now = now.Add(time.Second)
+	rec = get(nil)
+	lastModified := rec.Header().Get("Last-Modified")
+	require.Equal(t, "Tue, 20 Aug 2024 23:16:00 GMT", lastModified)
+	rec = get(http.Header{"If-Modified-Since": {lastModified}})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestHandler_Alert(t *testing.T) {
+	handler := scraper.NewHandler(scraper.NewAlertData(nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/alerts/1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status scraper.Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Equal(t, region.Crimea, status.Region)
+	require.True(t, status.Enabled())
+}
+
+func TestHandler_Errors(t *testing.T) {
+	handler := scraper.NewHandler(scraper.NewAlertData([]region.ID{region.Odesa}))
+
+	tests := []struct {
+		path  string
+		code  int
+		error string
+	}{
+		{"/alerts/odesa", http.StatusBadRequest, "invalid region"},
+		{"/alerts/42", http.StatusNotFound, "region not found"},
+		{"/alerts/26", http.StatusNotFound, "region not found"}, // valid, but not seeded
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+			require.Equal(t, test.code, rec.Code)
+			require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			require.JSONEq(t, `{"error":"`+test.error+`"}`, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_Stream(t *testing.T) {
+	alertData := scraper.NewAlertData(nil)
+	server := httptest.NewServer(scraper.NewHandler(alertData))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/alerts/stream", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (event string, data []byte) {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = []byte(strings.TrimPrefix(line, "data: "))
+			}
+		}
+	}
+
+	event, data := readEvent()
+	require.Equal(t, "snapshot", event)
+	var snapshot []scraper.Status
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+	require.Len(t, snapshot, region.Count())
+
+	enabled := scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00")}
+	alertData.Set(enabled)
+	event, data = readEvent()
+	require.Equal(t, "status", event)
+	var status scraper.Status
+	require.NoError(t, json.Unmarshal(data, &status))
+	require.Equal(t, region.Odesa, status.Region)
+	require.True(t, status.Enabled())
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return alertData.SubscribersCount() == 0
+	}, time.Second, time.Millisecond, "subscription must be released on disconnect")
+}
@@ -0,0 +1,26 @@
+package scraper
+
+// Level is the alert level of a region, ordered by severity.
+type Level int
+
+const (
+	LevelNone    Level = iota // no alert
+	LevelWarning              // threat warning preceding an alert, doesn't enable the alert
+	LevelPartial              // alert is cleared in the region, but still active in some of its communities
+	LevelFull                 // alert in the whole region
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelWarning:
+		return "warning"
+	case LevelPartial:
+		return "partial"
+	case LevelFull:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/zelenin/go-tdlib/client"
+)
+
+// MessageExtractor is a source-independent view of a channel message, so messages can be parsed
+// without tdlib types, e.g. from an alternative source or in tests.
+type MessageExtractor interface {
+	Text() string    // text or caption, empty if the message has none
+	Date() time.Time // when the message was sent
+	ID() int64
+	ChatID() int64
+	IsForward() bool
+}
+
+// ParseMessage parses the alert statuses of the message, see ParseAlertText.
+// Status.SourceMessageID of the statuses is the message's ID.
+// Returns nil if the message has no text. The message's chat and forward origin are not checked.
+func ParseMessage(message MessageExtractor) ([]Status, error) {
+	return parseMessage(message, defaultParseOptions)
+}
+
+func parseMessage(message MessageExtractor, opts parseOptions) ([]Status, error) {
+	text := message.Text()
+	if text == "" {
+		return nil, nil
+	}
+	statuses, err := parseAlertText(text, message.Date(), opts)
+	for i := range statuses {
+		statuses[i].SourceMessageID = message.ID()
+	}
+	return statuses, err
+}
+
+// tdlibMessage adapts a tdlib message to MessageExtractor.
+type tdlibMessage struct {
+	message *client.Message
+}
+
+// NewTdlibMessage wraps a tdlib message into a MessageExtractor.
+func NewTdlibMessage(message *client.Message) MessageExtractor {
+	return tdlibMessage{message: message}
+}
+
+func (m tdlibMessage) Text() string {
+	text, _ := messageText(m.message.Content)
+	return text
+}
+
+func (m tdlibMessage) Date() time.Time {
+	return time.Unix(int64(m.message.Date), 0)
+}
+
+func (m tdlibMessage) ID() int64 {
+	return m.message.Id
+}
+
+func (m tdlibMessage) ChatID() int64 {
+	return m.message.ChatId
+}
+
+func (m tdlibMessage) IsForward() bool {
+	return m.message.ForwardInfo != nil
+}
+
+// messageText returns the text of a text message, or the caption of a photo, video or animation.
+func messageText(content client.MessageContent) (string, bool) {
+	var text *client.FormattedText
+	switch content := content.(type) {
+	case *client.MessageText:
+		text = content.Text
+	case *client.MessagePhoto:
+		text = content.Caption
+	case *client.MessageVideo:
+		text = content.Caption
+	case *client.MessageAnimation:
+		text = content.Caption
+	}
+	if text == nil {
+		return "", false
+	}
+	return text.Text, true
+}
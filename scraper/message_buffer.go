@@ -0,0 +1,42 @@
+package scraper
+
+import (
+	"sync"
+
+	"github.com/zelenin/go-tdlib/client"
+)
+
+// messageBuffer is a fixed size ring buffer of messages.
+type messageBuffer struct {
+	lock     sync.Mutex
+	messages []*client.Message
+	next     int
+}
+
+func newMessageBuffer(size int) *messageBuffer {
+	return &messageBuffer{
+		messages: make([]*client.Message, 0, size),
+	}
+}
+
+// add appends the message, overwriting the oldest one if the buffer is full.
+func (r *messageBuffer) add(message *client.Message) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.messages) < cap(r.messages) {
+		r.messages = append(r.messages, message)
+		return
+	}
+	r.messages[r.next] = message
+	r.next = (r.next + 1) % len(r.messages)
+}
+
+// all returns buffered messages from the oldest to the newest.
+func (r *messageBuffer) all() []*client.Message {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	messages := make([]*client.Message, 0, len(r.messages))
+	messages = append(messages, r.messages[r.next:]...)
+	messages = append(messages, r.messages[:r.next]...)
+	return messages
+}
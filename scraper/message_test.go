@@ -0,0 +1,59 @@
+package scraper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zelenin/go-tdlib/client"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// fakeMessage is a MessageExtractor independent of tdlib.
+type fakeMessage struct {
+	text string
+	date time.Time
+}
+
+func (m fakeMessage) Text() string    { return m.text }
+func (m fakeMessage) Date() time.Time { return m.date }
+func (m fakeMessage) ID() int64       { return 1 }
+func (m fakeMessage) ChatID() int64   { return airAlertUaChannelID }
+func (m fakeMessage) IsForward() bool { return false }
+
+func TestParseMessage(t *testing.T) {
+	statuses, err := scraper.ParseMessage(fakeMessage{
+		text: "🔴 02:15 Повітряна тривога в Одеська область\n🟢 02:16 Відбій тривоги в м. Київ.",
+		date: strToDate("2024-08-21 02:16:19"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-21 02:15:00"), Marker: '🔴', SourceMessageID: 1},
+		{Region: region.KyivCity, Level: scraper.LevelNone, UpdatedAt: strToDate("2024-08-21 02:16:00"), Marker: '🟢', SourceMessageID: 1},
+	}, statuses)
+
+	statuses, err = scraper.ParseMessage(fakeMessage{date: strToDate("2024-08-21 02:16:19")})
+	require.NoError(t, err)
+	require.Nil(t, statuses, "message without text")
+
+	_, err = scraper.ParseMessage(fakeMessage{text: "🔴 99:99 Повітряна тривога в м. Київ"})
+	require.Error(t, err)
+}
+
+func TestNewTdlibMessage(t *testing.T) {
+	message := createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19"))
+	message.Id = 42
+	extracted := scraper.NewTdlibMessage(message)
+	require.Equal(t, "🔴 02:15 Повітряна тривога в Одеська область", extracted.Text())
+	require.True(t, strToDate("2024-08-21 02:15:19").Equal(extracted.Date()))
+	require.Equal(t, int64(42), extracted.ID())
+	require.Equal(t, airAlertUaChannelID, extracted.ChatID())
+	require.False(t, extracted.IsForward())
+
+	message.ForwardInfo = &client.MessageForwardInfo{}
+	message.Content = &client.MessagePhoto{}
+	require.True(t, extracted.IsForward())
+	require.Empty(t, extracted.Text())
+}
@@ -0,0 +1,70 @@
+// Package metrics exposes scraper performance metrics to Prometheus.
+// It's a separate package, so users of the scraper don't depend on the Prometheus client unless they import it.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mineroot/alert-data/scraper"
+)
+
+// Tracer is a scraper.Tracer observing durations of the scraper phases in Prometheus histograms:
+//
+//	alert_data_message_processing_seconds  parsing of a single message, both history and real-time
+//	alert_data_history_backfill_seconds    fetching and applying the whole history
+//
+// Use it with scraper.WithTracer.
+type Tracer struct {
+	messageProcessing prometheus.Histogram
+	historyBackfill   prometheus.Histogram
+}
+
+var _ scraper.Tracer = (*Tracer)(nil)
+
+// NewTracer creates a Tracer and registers its histograms with the registerer.
+// Panics if the histograms are already registered.
+func NewTracer(registerer prometheus.Registerer) *Tracer {
+	tracer := &Tracer{
+		messageProcessing: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "alert_data_message_processing_seconds",
+			Help:    "Duration of processing a single channel message.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8), // 100µs to ~1.6s
+		}),
+		historyBackfill: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "alert_data_history_backfill_seconds",
+			Help:    "Duration of fetching and applying the channel history.",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10), // 0.5s to ~4m
+		}),
+	}
+	registerer.MustRegister(tracer.messageProcessing, tracer.historyBackfill)
+	return tracer
+}
+
+// Start starts a span observing its duration on End if the span is a measured scraper phase.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, scraper.Span) {
+	var histogram prometheus.Histogram
+	switch name {
+	case "parseMessage":
+		histogram = t.messageProcessing
+	case "history":
+		histogram = t.historyBackfill
+	}
+	return ctx, &span{histogram: histogram, start: time.Now()}
+}
+
+type span struct {
+	histogram prometheus.Histogram
+	start     time.Time
+}
+
+func (s *span) SetAttribute(string, any) {}
+func (s *span) RecordError(error)        {}
+
+func (s *span) End() {
+	if s.histogram != nil {
+		s.histogram.Observe(time.Since(s.start).Seconds())
+	}
+}
@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper/metrics"
+)
+
+func TestTracer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	tracer := metrics.NewTracer(registry)
+	require.Panics(t, func() {
+		metrics.NewTracer(registry)
+	}, "histograms must be registered once")
+
+	for _, name := range []string{"parseMessage", "parseMessage", "history", "GetChatHistory"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.SetAttribute("key", "value")
+		span.End()
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	histograms := make(map[string]*dto.Histogram)
+	for _, family := range families {
+		require.Equal(t, dto.MetricType_HISTOGRAM, family.GetType())
+		require.Len(t, family.GetMetric(), 1)
+		histograms[family.GetName()] = family.GetMetric()[0].GetHistogram()
+	}
+	require.Len(t, histograms, 2)
+
+	for name, expected := range map[string]uint64{
+		"alert_data_message_processing_seconds": 2,
+		"alert_data_history_backfill_seconds":   1,
+	} {
+		histogram := histograms[name]
+		require.NotNil(t, histogram, name)
+		require.Equal(t, expected, histogram.GetSampleCount(), name)
+
+		bounds := make([]float64, 0, len(histogram.GetBucket()))
+		for _, bucket := range histogram.GetBucket() {
+			bounds = append(bounds, bucket.GetUpperBound())
+		}
+		require.GreaterOrEqual(t, len(bounds), 5, name)
+		require.True(t, slices.IsSorted(bounds), name)
+		require.Greater(t, bounds[0], 0.0, name)
+		require.LessOrEqual(t, bounds[len(bounds)-1], 600.0, name)
+	}
+}
@@ -0,0 +1,75 @@
+package scraper
+
+// NationalLevel is an aggregate alert level of the whole country based on the number of active alerts.
+type NationalLevel int
+
+const (
+	NationalCalm NationalLevel = iota
+	NationalElevated
+	NationalHigh
+	NationalCritical
+)
+
+func (l NationalLevel) String() string {
+	switch l {
+	case NationalCalm:
+		return "calm"
+	case NationalElevated:
+		return "elevated"
+	case NationalHigh:
+		return "high"
+	case NationalCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// NationalThresholds holds the minimum number of regions with active alerts for each NationalLevel.
+// Fewer than Elevated active regions is NationalCalm.
+// The long-running alerts in the occupied Crimea and Luhansk regions are not counted.
+type NationalThresholds struct {
+	Elevated int
+	High     int
+	Critical int
+}
+
+// DefaultNationalThresholds are used by AlertData.NationalLevel:
+// calm (0 active), elevated (1-3), high (4-10), critical (>10).
+var DefaultNationalThresholds = NationalThresholds{
+	Elevated: 1,
+	High:     4,
+	Critical: 11,
+}
+
+// Level returns NationalLevel for the given number of regions with active alerts.
+func (t NationalThresholds) Level(active int) NationalLevel {
+	switch {
+	case active >= t.Critical:
+		return NationalCritical
+	case active >= t.High:
+		return NationalHigh
+	case active >= t.Elevated:
+		return NationalElevated
+	default:
+		return NationalCalm
+	}
+}
+
+// NationalLevel returns the aggregate alert level using DefaultNationalThresholds.
+func (r *AlertData) NationalLevel() NationalLevel {
+	return r.NationalLevelWith(DefaultNationalThresholds)
+}
+
+// NationalLevelWith returns the aggregate alert level using the given thresholds.
+func (r *AlertData) NationalLevelWith(thresholds NationalThresholds) NationalLevel {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	active := 0
+	for id, status := range r.data.Snapshot() {
+		if status.Enabled() && !isLongRunningAlert(id) {
+			active++
+		}
+	}
+	return thresholds.Level(active)
+}
@@ -0,0 +1,226 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+var alertStatusRegexp = regexp.MustCompile(`(?m)^[🔴🟢🟡] (\d\d:\d\d) (Відбій тривоги|Повітряна тривога) в (.*?)\.?$`)
+
+// drillMarker marks messages of exercises, e.g. "🔴 10:00 Повітряна тривога в Одеська область (навчальна)".
+const drillMarker = "(навчальна)"
+
+// partialClearMarker marks a clear message of the region where the alert is still active in some communities, e.g.
+// "🟡 10:42 Відбій тривоги в Дніпропетровська область.\nЗверніть увагу, тривога ще триває у: ...".
+const partialClearMarker = '🟡'
+
+// threatKeywords maps lowercase keywords of the message text to the threat type, in order of priority,
+// e.g. "Загроза застосування балістичного озброєння" is "ballistic".
+var threatKeywords = []struct {
+	keyword    string
+	threatType string
+}{
+	{"балістичн", "ballistic"},
+	{"ракет", "missile"},
+	{"бпла", "uav"},
+	{"шахед", "uav"},
+	{"дрон", "uav"},
+	{"міг-31", "mig"},
+	{"авіаці", "aviation"},
+}
+
+// parseThreatType returns the threat type of the first threat keyword found in text, or empty string if there is none.
+func parseThreatType(text string) string {
+	text = strings.ToLower(text)
+	for _, threat := range threatKeywords {
+		if strings.Contains(text, threat.keyword) {
+			return threat.threatType
+		}
+	}
+	return ""
+}
+
+var warningRegexp = regexp.MustCompile(`(?m)^[🔴🟢🟡] (\d\d:\d\d) (Загроза застосування) .*? (?:в|для) (.*?)\.?$`)
+
+// invisibleReplacer strips invisible characters Telegram may add to the text, e.g. a variation selector after 🔴,
+// which would otherwise prevent the markers from matching.
+var invisibleReplacer = strings.NewReplacer(
+	"\uFE0E", "", // text variation selector
+	"\uFE0F", "", // emoji variation selector
+	"\u200B", "", // zero-width space
+	"\u200C", "", // zero-width non-joiner
+	"\u200D", "", // zero-width joiner
+	"\u2060", "", // word joiner
+	"\uFEFF", "", // zero-width no-break space
+)
+
+// ParseAlertText parses text of the air_alert_ua channel message sent at messageAt.
+// Returns statuses of all regions listed in the text, or nil if the text isn't an alert status update.
+// Threat warnings ("Загроза застосування ...") produce statuses with LevelWarning,
+// clears marked with 🟡 (the alert is still active in some communities of the region) produce LevelPartial.
+// Statuses of drill messages (marked with "(навчальна)") have IsDrill set.
+// ThreatType of enabled alerts and warnings is set if the text mentions a known threat,
+// e.g. "ballistic" for "балістичного озброєння"; it's always empty for clears.
+// Returns an error if the text looks like a status update, but its time can't be parsed.
+// Variation selectors and zero-width characters are ignored.
+// Message times have no date, so the date is taken from messageAt in Europe/Kyiv,
+// or the previous day if the time would be more than DefaultFutureTolerance after messageAt, e.g. "23:59" sent at 00:01.
+func ParseAlertText(text string, messageAt time.Time) ([]Status, error) {
+	return parseAlertText(text, messageAt, defaultParseOptions)
+}
+
+// DefaultFutureTolerance is how far the parsed message time may be after the message date,
+// e.g. due to clock skew, before it's considered to belong to the previous day. See WithFutureTolerance.
+const DefaultFutureTolerance = 5 * time.Minute
+
+// parseOptions are the parsing settings of TgScraper, see WithFutureTolerance and WithTimeFallback.
+type parseOptions struct {
+	futureTolerance time.Duration
+	timeFallback    bool // use messageAt if the message time can't be parsed, instead of an error
+}
+
+var defaultParseOptions = parseOptions{
+	futureTolerance: DefaultFutureTolerance,
+	timeFallback:    false,
+}
+
+func parseAlertText(text string, messageAt time.Time, opts parseOptions) ([]Status, error) {
+	text = invisibleReplacer.Replace(text)
+	var statuses []Status
+	drill := strings.Contains(text, drillMarker)
+	threatType := parseThreatType(text)
+	matches := alertStatusRegexp.FindAllStringSubmatch(text, -1)
+	matches = append(matches, warningRegexp.FindAllStringSubmatch(text, -1)...)
+	for _, match := range matches {
+		status, err := parseMatch(match, messageAt, opts)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			continue
+		}
+		status.IsDrill = drill
+		if status.Enabled() || status.Level == LevelWarning {
+			status.ThreatType = threatType
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// parseMatch parses a single alertStatusRegexp match (one region line) of the message sent at messageAt.
+func parseMatch(match []string, messageAt time.Time, opts parseOptions) (*Status, error) {
+	if len(match) < 4 {
+		return nil, nil
+	}
+
+	var updatedAt time.Time
+	timeOnly := match[1] + ":00"
+	parsedTime, err := time.Parse(time.TimeOnly, timeOnly)
+	switch {
+	case err == nil:
+		updatedAt = parseTimeOfDay(parsedTime, messageAt, opts.futureTolerance)
+	case opts.timeFallback:
+		updatedAt = messageAt.In(kyivLocation()).Truncate(time.Minute)
+	default:
+		return nil, fmt.Errorf("failed to parse time: %s: %w", timeOnly, err)
+	}
+
+	marker, _ := utf8.DecodeRuneInString(match[0])
+
+	raidStatusStr := match[2]
+	var level Level
+	switch raidStatusStr {
+	case "Відбій тривоги":
+		level = LevelNone
+		if marker == partialClearMarker {
+			level = LevelPartial
+		}
+	case "Повітряна тривога":
+		level = LevelFull
+	case "Загроза застосування":
+		level = LevelWarning
+	default:
+		return nil, nil
+	}
+
+	regionStr := strings.TrimSpace(strings.TrimSuffix(match[3], drillMarker))
+	regionId := region.ParseName(regionStr)
+	if regionId == region.Invalid {
+		return nil, nil
+	}
+
+	return &Status{
+		Region:    regionId,
+		Level:     level,
+		UpdatedAt: updatedAt,
+		Marker:    marker,
+	}, nil
+}
+
+// parseTimeOfDay returns the time of day on the date of messageAt in Europe/Kyiv.
+// If the result is more than futureTolerance after messageAt, the time belongs to the previous day,
+// e.g. the message with "23:59" delayed until 00:01.
+func parseTimeOfDay(timeOfDay, messageAt time.Time, futureTolerance time.Duration) time.Time {
+	messageAt = messageAt.In(kyivLocation())
+	updatedAt := time.Date(
+		messageAt.Year(), messageAt.Month(), messageAt.Day(),
+		timeOfDay.Hour(), timeOfDay.Minute(),
+		0, 0, kyivLocation(),
+	)
+	if updatedAt.Sub(messageAt) > futureTolerance {
+		updatedAt = time.Date(
+			messageAt.Year(), messageAt.Month(), messageAt.Day()-1,
+			timeOfDay.Hour(), timeOfDay.Minute(),
+			0, 0, kyivLocation(),
+		)
+	}
+	return updatedAt
+}
+
+// summaryHeaderRegexp matches the header of a summary listing all regions with an active alert, one per line:
+//
+//	Тривога триває в:
+//	🔴 Харківська область
+//	🔴 м. Київ
+var summaryHeaderRegexp = regexp.MustCompile(`(?m)^Тривога триває в:$`)
+
+var summaryRegionRegexp = regexp.MustCompile(`(?m)^(?:🔴|-) (.+?)\.?$`)
+
+// parseSummaryMessage parses the summary of active alerts sent at messageAt, see summaryHeaderRegexp.
+// Returns history statuses of all regions: listed ones with LevelFull, others with LevelNone, all updated at messageAt.
+// ok is false if the text isn't a summary.
+func parseSummaryMessage(text string, messageAt time.Time) (statuses []Status, ok bool) {
+	text = invisibleReplacer.Replace(text)
+	header := summaryHeaderRegexp.FindStringIndex(text)
+	if header == nil {
+		return nil, false
+	}
+	active := make(map[region.ID]bool)
+	for _, match := range summaryRegionRegexp.FindAllStringSubmatch(text[header[1]:], -1) {
+		if id := region.ParseName(match[1]); id != region.Invalid {
+			active[id] = true
+		}
+	}
+
+	updatedAt := messageAt.In(kyivLocation()).Truncate(time.Minute)
+	statuses = make([]Status, 0, region.Count())
+	for id := range region.SortedIterator() {
+		level := LevelNone
+		if active[id] {
+			level = LevelFull
+		}
+		statuses = append(statuses, Status{
+			Region:    id,
+			Level:     level,
+			UpdatedAt: updatedAt,
+			IsHistory: true,
+		})
+	}
+	return statuses, true
+}
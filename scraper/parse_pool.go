@@ -0,0 +1,68 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zelenin/go-tdlib/client"
+)
+
+// parsePool parses messages concurrently by a fixed number of workers.
+// Results are received via per-message channels, so the caller can apply them in the order of messages.
+type parsePool struct {
+	jobs chan parseJob
+	wg   sync.WaitGroup
+}
+
+type parseJob struct {
+	message *client.Message
+	result  chan<- parseResult
+}
+
+type parseResult struct {
+	message  *client.Message
+	statuses []Status
+	err      error
+}
+
+func (r *TgScraper) newParsePool(ctx context.Context, workers int) *parsePool {
+	pool := &parsePool{
+		jobs: make(chan parseJob, workers),
+	}
+	pool.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer pool.wg.Done()
+			for job := range pool.jobs {
+				statuses, err := r.parseMessage(ctx, job.message)
+				job.result <- parseResult{message: job.message, statuses: statuses, err: err}
+			}
+		}()
+	}
+	return pool
+}
+
+// submit queues the message for parsing, blocking while all workers are busy or until ctx is done.
+// The returned channel receives the result.
+func (p *parsePool) submit(ctx context.Context, message *client.Message) <-chan parseResult {
+	result := make(chan parseResult, 1) // workers never block on sending results
+	select {
+	case p.jobs <- parseJob{message: message, result: result}:
+	case <-ctx.Done():
+	}
+	return result
+}
+
+// close stops the workers and waits for them to exit.
+func (p *parsePool) close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
+
+// firstResult returns the oldest pending result channel, or nil if there are none.
+func firstResult(pending []<-chan parseResult) <-chan parseResult {
+	if len(pending) == 0 {
+		return nil
+	}
+	return pending[0]
+}
@@ -0,0 +1,234 @@
+package scraper_test
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestParseAlertText(t *testing.T) {
+	statuses, err := scraper.ParseAlertText(
+		"🟢 19:46 Відбій тривоги в Одеська область.\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
+		strToDate("2024-08-19 19:46:52"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []scraper.Status{{
+		Region:    region.Odesa,
+		Level:     scraper.LevelNone,
+		UpdatedAt: strToDate("2024-08-19 19:46:00"),
+		Marker:    '🟢',
+	}}, statuses)
+
+	statuses, err = scraper.ParseAlertText("Слідкуйте за подальшими повідомленнями.", strToDate("2024-08-19 19:46:52"))
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+
+	_, err = scraper.ParseAlertText("🔴 25:61 Повітряна тривога в м. Київ", strToDate("2024-08-19 19:46:52"))
+	require.Error(t, err)
+
+	statuses, err = scraper.ParseAlertText("🔴 19:40 Повітряна тривога в Одеській області.", strToDate("2024-08-19 19:46:52"))
+	require.NoError(t, err)
+	require.Equal(t, []scraper.Status{{
+		Region:    region.Odesa,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-19 19:40:00"),
+		Marker:    '🔴',
+	}}, statuses)
+}
+
+func TestParseAlertText_Warning(t *testing.T) {
+	statuses, err := scraper.ParseAlertText(
+		"🟡 12:34 Загроза застосування балістичного озброєння в Харківська область\nСлідкуйте за подальшими повідомленнями.",
+		strToDate("2024-08-22 12:34:10"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []scraper.Status{{
+		Region:     region.Kharkiv,
+		Level:      scraper.LevelWarning,
+		UpdatedAt:  strToDate("2024-08-22 12:34:00"),
+		Marker:     '🟡',
+		ThreatType: "ballistic",
+	}}, statuses)
+}
+
+func TestParseAlertText_DayBoundary(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		messageAt time.Time
+		expected  time.Time
+	}{
+		{"normal", "🔴 12:34 Повітряна тривога в Одеська область", strToDate("2024-08-22 12:34:10"), strToDate("2024-08-22 12:34:00")},
+		{"just after midnight", "🔴 23:59 Повітряна тривога в Одеська область", strToDate("2024-08-22 00:01:10"), strToDate("2024-08-21 23:59:00")},
+		{"delayed by minutes", "🔴 23:50 Повітряна тривога в Одеська область", strToDate("2024-08-22 00:10:00"), strToDate("2024-08-21 23:50:00")},
+		{"delayed on the same day", "🔴 12:20 Повітряна тривога в Одеська область", strToDate("2024-08-22 12:34:10"), strToDate("2024-08-22 12:20:00")},
+		{"clock skew", "🔴 12:38 Повітряна тривога в Одеська область", strToDate("2024-08-22 12:34:10"), strToDate("2024-08-22 12:38:00")},
+		{"message date in UTC", "🔴 01:30 Повітряна тривога в Одеська область", strToDate("2024-08-22 01:31:00").UTC(), strToDate("2024-08-22 01:30:00")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statuses, err := scraper.ParseAlertText(test.text, test.messageAt)
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, test.expected, statuses[0].UpdatedAt)
+		})
+	}
+}
+
+func FuzzParseAlertText(f *testing.F) {
+	seeds := []string{
+		"🟢 19:46 Відбій тривоги в Одеська область.\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
+		"🔴 02:15 Повітряна тривога в Одеська область\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
+		"🔴 08:39 Повітряна тривога в м. Київ\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
+		"🟢 10:06 Відбій тривоги в м. Київ.\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
+		"🔴 02:15 Повітряна тривога в Одеська область\n🔴 02:16 Повітряна тривога в Миколаївська область",
+		"🟡 12:00 Повітряна тривога в Курська Народна Республіка",
+		"🟡 12:34 Загроза застосування балістичного озброєння в Харківська область",
+		"🔴 99:99 Повітряна тривога в м. Київ",
+		"🔴 08:3",
+		"🔴",
+		"\xf0\x9f\x94",
+		"\xff\xfe 08:39 Повітряна тривога в м. Київ",
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed, int64(1724306401))
+	}
+
+	f.Fuzz(func(t *testing.T, text string, messageAtUnix int64) {
+		statuses, err := scraper.ParseAlertText(text, time.Unix(messageAtUnix, 0))
+		if err != nil {
+			require.Nil(t, statuses)
+			return
+		}
+		for _, status := range statuses {
+			require.NotEqual(t, region.Invalid, status.Region)
+			require.True(t, utf8.ValidString(status.Region.String()))
+		}
+	})
+}
+
+func TestParseAlertText_InvisibleCharacters(t *testing.T) {
+	tests := []string{
+		"🔴\uFE0F 02:15 Повітряна тривога в Одеська область",
+		"\u200B🔴 02:15 Повітряна тривога в Одеська область",
+		"🔴\u200D 02:15 Повітряна тривога в Одеська\u2060 область",
+		"\uFEFF🔴\uFE0F 02:15 Повітряна тривога в Одеська область\uFE0F",
+	}
+
+	for _, text := range tests {
+		t.Run(text, func(t *testing.T) {
+			statuses, err := scraper.ParseAlertText(text, strToDate("2024-08-21 02:15:19"))
+			require.NoError(t, err)
+			require.Equal(t, []scraper.Status{{
+				Region:    region.Odesa,
+				Level:     scraper.LevelFull,
+				UpdatedAt: strToDate("2024-08-21 02:15:00"),
+				Marker:    '🔴',
+			}}, statuses)
+		})
+	}
+}
+
+func TestParseAlertText_Level(t *testing.T) {
+	tests := []struct {
+		text  string
+		level scraper.Level
+	}{
+		{"🔴 02:15 Повітряна тривога в Одеська область", scraper.LevelFull},
+		{"🟢 02:45 Відбій тривоги в Одеська область.", scraper.LevelNone},
+		{"🟡 02:45 Відбій тривоги в Одеська область.\nЗверніть увагу, тривога ще триває у:\n- Білгород-Дністровська територіальна громада", scraper.LevelPartial},
+		{"🟡 12:34 Загроза застосування балістичного озброєння в Одеська область", scraper.LevelWarning},
+	}
+
+	for _, test := range tests {
+		t.Run(test.level.String(), func(t *testing.T) {
+			statuses, err := scraper.ParseAlertText(test.text, strToDate("2024-08-22 12:40:00"))
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, region.Odesa, statuses[0].Region)
+			require.Equal(t, test.level, statuses[0].Level)
+		})
+	}
+}
+
+func TestParseAlertText_Marker(t *testing.T) {
+	tests := []struct {
+		text   string
+		marker rune
+	}{
+		{"🔴 02:15 Повітряна тривога в Одеська область", '🔴'},
+		{"🟢 02:45 Відбій тривоги в Одеська область.", '🟢'},
+		{"🟡 02:15 Повітряна тривога в Одеська область", '🟡'},
+		{"🟡 12:34 Загроза застосування балістичного озброєння в Одеська область", '🟡'},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			statuses, err := scraper.ParseAlertText(test.text, strToDate("2024-08-22 12:40:00"))
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, test.marker, statuses[0].Marker)
+		})
+	}
+
+	status, err := scraper.NewAlertData(nil).GetByRegion(region.Odesa)
+	require.NoError(t, err)
+	require.Zero(t, status.Marker, "seeded status must have no marker")
+}
+
+func TestParseAlertText_ThreatType(t *testing.T) {
+	tests := []struct {
+		text       string
+		level      scraper.Level
+		threatType string
+	}{
+		{"🔴 02:15 Повітряна тривога в Одеська область", scraper.LevelFull, ""},
+		{"🟢 02:45 Відбій тривоги в Одеська область.", scraper.LevelNone, ""},
+		{"🟡 12:34 Загроза застосування балістичного озброєння в Одеська область", scraper.LevelWarning, "ballistic"},
+		{"🔴 02:15 Повітряна тривога в Одеська область\nЗагроза ударних БпЛА", scraper.LevelFull, "uav"},
+		{"🔴 02:15 Повітряна тривога в Одеська область\nЗліт МіГ-31К", scraper.LevelFull, "mig"},
+		{"🔴 02:15 Повітряна тривога в Одеська область\nКрилаті ракети в напрямку області", scraper.LevelFull, "missile"},
+		{"🟢 02:45 Відбій тривоги в Одеська область.\nЗагрозу балістичного озброєння знято", scraper.LevelNone, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			statuses, err := scraper.ParseAlertText(test.text, strToDate("2024-08-21 12:34:19"))
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			require.Equal(t, region.Odesa, statuses[0].Region)
+			require.Equal(t, test.level, statuses[0].Level)
+			require.Equal(t, test.threatType, statuses[0].ThreatType)
+		})
+	}
+}
+
+func TestParseSummaryMessage(t *testing.T) {
+	messageAt := strToDate("2024-08-22 12:00:42")
+	statuses, ok := scraper.ParseSummaryMessage(
+		"Станом на 12:00\nТривога триває в:\n🔴 Харківська область\n🔴 м. Київ\n🔴 Автономна Республіка Крим\n🔴 Луганська область.\n🔴 Курська Народна Республіка\n#summary",
+		messageAt,
+	)
+	require.True(t, ok)
+	require.Len(t, statuses, region.Count())
+
+	var active []region.ID
+	for _, status := range statuses {
+		require.True(t, status.IsHistory)
+		require.Equal(t, strToDate("2024-08-22 12:00:00"), status.UpdatedAt)
+		if status.Enabled() {
+			active = append(active, status.Region)
+		}
+	}
+	require.Equal(t, []region.ID{region.Crimea, region.Luhansk, region.Kharkiv, region.KyivCity}, active)
+
+	_, ok = scraper.ParseSummaryMessage("🔴 02:15 Повітряна тривога в Одеська область", messageAt)
+	require.False(t, ok)
+}
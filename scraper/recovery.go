@@ -0,0 +1,106 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// default backoff between RunWithRecovery attempts
+const (
+	recoveryMinBackoff = time.Second
+	recoveryMaxBackoff = time.Minute
+)
+
+// RecoveryPolicy sets how RunWithRecovery restarts the scraper after a fatal error.
+type RecoveryPolicy struct {
+	// MaxAttempts is the max number of consecutive failed attempts before giving up. Zero means unlimited.
+	MaxAttempts int
+	// MinBackoff is the backoff after the first failed attempt, doubled after each next one. Default is 1s.
+	MinBackoff time.Duration
+	// MaxBackoff caps the backoff. Default is 1m.
+	MaxBackoff time.Duration
+	// Recoverable reports whether the scraper is restarted after err.
+	// Default treats all errors as recoverable, except context errors, ErrChatMismatch and ErrUnauthorized.
+	// Context errors are never recoverable.
+	Recoverable func(err error) bool
+}
+
+func (p RecoveryPolicy) recoverable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Recoverable != nil {
+		return p.Recoverable(err)
+	}
+	return !errors.Is(err, ErrChatMismatch) && !errors.Is(err, ErrUnauthorized)
+}
+
+// backoff returns the backoff after the failed attempt, starting from 1.
+func (p RecoveryPolicy) backoff(attempt int) time.Duration {
+	minBackoff := p.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = recoveryMinBackoff
+	}
+	return min(minBackoff<<min(attempt-1, 20), p.maxBackoff())
+}
+
+func (p RecoveryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return recoveryMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+// RunWithRecovery starts the scraper like Run, but restarts it according to policy after recoverable errors:
+// the chat is verified and the history is fetched again, and a new listener is acquired from the client.
+// AlertData, UpdatesChan() and Errors() are kept across restarts, recovered errors are reported on Errors().
+// Returns when ctx is cancelled, the error is unrecoverable or policy.MaxAttempts is exceeded.
+func (r *TgScraper) RunWithRecovery(ctx context.Context, policy RecoveryPolicy) error {
+	if r.client == nil {
+		panic("scraper: use scraper.NewTgScraper() to create *TgScraper instance")
+	}
+	if ctx == nil {
+		panic("scraper: nil context")
+	}
+	var err error
+	r.once.Do(func() {
+		err = r.runWithRecovery(ctx, policy)
+	})
+
+	if err != nil {
+		return fmt.Errorf("scraper: %w", err)
+	}
+	return nil
+}
+
+func (r *TgScraper) runWithRecovery(ctx context.Context, policy RecoveryPolicy) error {
+	defer r.stop()
+	for attempt := 1; ; attempt++ {
+		startedAt := time.Now()
+		err := r.runOnce(ctx)
+		if err == nil || !policy.recoverable(err) {
+			return err
+		}
+		if attempt > 1 && time.Since(startedAt) > policy.maxBackoff() {
+			attempt = 1 // the attempt ran longer than the max backoff, so the scraper had recovered
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		backoff := policy.backoff(attempt)
+		r.reportError(fmt.Errorf("scraper: recovering: %w", err))
+		r.logger.Warn("scraper: restarting after error",
+			slog.Any("error", err),
+			slog.Duration("backoff", backoff),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
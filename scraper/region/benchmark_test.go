@@ -0,0 +1,71 @@
+package region_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func benchmarkParseName(b *testing.B, name string) {
+	b.ReportAllocs()
+	for range b.N {
+		if region.ParseName(name) == region.Invalid {
+			b.Fatalf("unable to parse %q", name)
+		}
+	}
+}
+
+func BenchmarkParseName_Nominative(b *testing.B) {
+	benchmarkParseName(b, "Одеська область")
+}
+
+func BenchmarkParseName_Genitive(b *testing.B) {
+	benchmarkParseName(b, "Одеської області")
+}
+
+func BenchmarkParseName_Locative(b *testing.B) {
+	benchmarkParseName(b, "Одеській області")
+}
+
+func BenchmarkParseName_Alias(b *testing.B) {
+	benchmarkParseName(b, "Автономна республіка Крим")
+}
+
+func BenchmarkParseName_Invalid(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		if region.ParseName("Курська Народна Республіка") != region.Invalid {
+			b.Fatal("unexpected region")
+		}
+	}
+}
+
+func BenchmarkParseId(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		if region.ParseId(26) == region.Invalid {
+			b.Fatal("unable to parse id")
+		}
+	}
+}
+
+func BenchmarkParse_UnmarshalJSON(b *testing.B) {
+	data := []byte(`"м. Київ"`)
+	b.ReportAllocs()
+	for range b.N {
+		var id region.ID
+		if err := json.Unmarshal(data, &id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse_ByCommand(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		if len(region.ByCommand(region.CommandNorth)) == 0 {
+			b.Fatal("no regions")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package region
+
+import (
+	"strings"
+)
+
+var idsByCode = make(map[string]ID, len(metadataById))
+
+func init() {
+	for id, metadata := range metadataById {
+		idsByCode[metadata.Code] = id
+	}
+}
+
+// Code returns the ISO 3166-2 code of the region, e.g. "UA-51".
+// Returns an empty string if the ID is invalid.
+func (id ID) Code() string {
+	return metadataById[id].Code
+}
+
+// ParseCode converts an ISO 3166-2 code (case-insensitive, e.g. "UA-51" or "ua-51") to its corresponding ID.
+// Returns Invalid ID if the code is not found.
+func ParseCode(code string) ID {
+	if id, exists := idsByCode[strings.ToUpper(strings.TrimSpace(code))]; exists {
+		return id
+	}
+	return Invalid
+}
+
+// ParseHashtag converts a region hashtag of channel messages, e.g. "#Одеська_область" or "#м_Київ",
+// to its corresponding ID. The leading "#" is optional.
+// Returns Invalid ID if the hashtag is not found.
+func ParseHashtag(tag string) ID {
+	name := strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(tag), "#"), "_", " ")
+	if city, ok := strings.CutPrefix(name, "м "); ok {
+		name = "м. " + city // the period is dropped in hashtags
+	}
+	if name == "" || strings.Contains(name, "#") {
+		return Invalid
+	}
+	return ParseName(name)
+}
@@ -0,0 +1,58 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestParseCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected region.ID
+	}{
+		{"UA-51", region.Odesa},
+		{"ua-51", region.Odesa},
+		{" UA-30 ", region.KyivCity},
+		{"UA-43", region.Crimea},
+		{"51", region.Invalid},
+		{"UA-99", region.Invalid},
+		{"", region.Invalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			assert.Equal(t, test.expected, region.ParseCode(test.code))
+		})
+	}
+
+	for id := range region.Iterator() {
+		assert.Equal(t, id, region.ParseCode(id.Code()), id.String())
+	}
+	assert.Empty(t, region.Invalid.Code())
+}
+
+func TestParseHashtag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected region.ID
+	}{
+		{"#Одеська_область", region.Odesa},
+		{"Одеська_область", region.Odesa},
+		{"#Івано-Франківська_область", region.IvanoFrankivsk},
+		{"#м_Київ", region.KyivCity},
+		{"#м_Севастополь", region.SevastopolCity},
+		{"#Автономна_Республіка_Крим", region.Crimea},
+		{"##Одеська_область", region.Invalid},
+		{"#", region.Invalid},
+		{"#Курська_Народна_Республіка", region.Invalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			assert.Equal(t, test.expected, region.ParseHashtag(test.tag))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package region
+
+import (
+	"slices"
+)
+
+// Constants representing operational commands of the Armed Forces of Ukraine.
+const (
+	InvalidCommand = Command(0)
+	CommandNorth   = Command(1)
+	CommandSouth   = Command(2)
+	CommandEast    = Command(3)
+	CommandWest    = Command(4)
+)
+
+var commandNames = map[Command]string{
+	CommandNorth: "North",
+	CommandSouth: "South",
+	CommandEast:  "East",
+	CommandWest:  "West",
+}
+
+var commandsById = map[ID]Command{
+	Crimea:         CommandSouth,
+	Vinnytsia:      CommandNorth,
+	Volyn:          CommandWest,
+	Dnipro:         CommandEast,
+	Donetsk:        CommandEast,
+	Zhytomyr:       CommandNorth,
+	Zakarpattia:    CommandWest,
+	Zaporizhzhia:   CommandEast,
+	IvanoFrankivsk: CommandWest,
+	Kyiv:           CommandNorth,
+	Kirovohrad:     CommandSouth,
+	Luhansk:        CommandEast,
+	Lviv:           CommandWest,
+	Mykolaiv:       CommandSouth,
+	Odesa:          CommandSouth,
+	Poltava:        CommandNorth,
+	Rivne:          CommandWest,
+	Sumy:           CommandNorth,
+	Ternopil:       CommandWest,
+	Kharkiv:        CommandNorth,
+	Kherson:        CommandSouth,
+	Khmelnytskyi:   CommandWest,
+	Cherkasy:       CommandNorth,
+	Chernivtsi:     CommandWest,
+	Chernihiv:      CommandNorth,
+	KyivCity:       CommandNorth,
+	SevastopolCity: CommandSouth,
+}
+
+var idsByCommand = make(map[Command][]ID, len(commandNames))
+
+func init() {
+	for id, command := range commandsById {
+		idsByCommand[command] = append(idsByCommand[command], id)
+	}
+	for _, ids := range idsByCommand {
+		slices.Sort(ids)
+	}
+}
+
+// Command represents an operational command the region belongs to.
+type Command int
+
+// String returns the name of the command.
+// Returns an empty string if the command is invalid.
+func (c Command) String() string {
+	return commandNames[c]
+}
+
+// Command returns the operational command the region belongs to.
+// Returns InvalidCommand if the ID is invalid.
+func (id ID) Command() Command {
+	return commandsById[id]
+}
+
+// ByCommand returns IDs of the regions belonging to the command, sorted in ascending order.
+// The returned slice is a fresh copy.
+func ByCommand(c Command) []ID {
+	ids := slices.Clone(idsByCommand[c])
+	if ids == nil {
+		ids = make([]ID, 0)
+	}
+	return ids
+}
@@ -0,0 +1,35 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestByCommand(t *testing.T) {
+	seen := make(map[region.ID]int)
+	for _, command := range []region.Command{
+		region.CommandNorth,
+		region.CommandSouth,
+		region.CommandEast,
+		region.CommandWest,
+	} {
+		assert.NotEmpty(t, command.String())
+		for _, id := range region.ByCommand(command) {
+			assert.Equal(t, command, id.Command())
+			seen[id]++
+		}
+	}
+
+	// assert every region belongs to exactly one command
+	assert.Len(t, seen, region.Count())
+	for id, _ := range region.Iterator() {
+		assert.Equal(t, 1, seen[id], id.String())
+	}
+
+	assert.Equal(t, region.InvalidCommand, region.Invalid.Command())
+	assert.Empty(t, region.ByCommand(region.InvalidCommand))
+	assert.Empty(t, region.InvalidCommand.String())
+}
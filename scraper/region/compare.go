@@ -0,0 +1,18 @@
+package region
+
+import (
+	"cmp"
+)
+
+// CompareID returns -1 if a is less than b, 0 if they're equal and +1 if a is greater than b,
+// e.g. for slices.SortFunc.
+func CompareID(a, b ID) int {
+	return cmp.Compare(a, b)
+}
+
+// IDSlice attaches the methods of sort.Interface to []ID, sorting in ascending order.
+type IDSlice []ID
+
+func (s IDSlice) Len() int           { return len(s) }
+func (s IDSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s IDSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,42 @@
+package region_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func shuffledIds() []region.ID {
+	ids := make([]region.ID, 0, region.Count())
+	for id := range region.SortedIterator() {
+		ids = append(ids, id)
+	}
+	rnd := rand.New(rand.NewPCG(1, 2))
+	rnd.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	return ids
+}
+
+func TestCompareID(t *testing.T) {
+	assert.Equal(t, -1, region.CompareID(region.Crimea, region.Odesa))
+	assert.Equal(t, 0, region.CompareID(region.Odesa, region.Odesa))
+	assert.Equal(t, 1, region.CompareID(region.KyivCity, region.Kyiv))
+
+	ids := shuffledIds()
+	slices.SortFunc(ids, region.CompareID)
+	assert.True(t, slices.IsSorted(ids))
+	assert.Len(t, ids, region.Count())
+}
+
+func TestIDSlice(t *testing.T) {
+	ids := shuffledIds()
+	sort.Sort(region.IDSlice(ids))
+	assert.True(t, slices.IsSorted(ids))
+	assert.Len(t, ids, region.Count())
+}
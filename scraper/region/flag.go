@@ -0,0 +1,22 @@
+package region
+
+import (
+	"fmt"
+)
+
+// Set parses a region name or its numeric id, so *ID implements flag.Value (and pflag.Value),
+// e.g. flag.Var(&id, "region", "region name or id").
+// Returns an error if the value doesn't resolve to a valid region.
+func (id *ID) Set(value string) error {
+	parsed := ParseNameOrId(value)
+	if parsed == Invalid {
+		return fmt.Errorf("region: invalid region %q", value)
+	}
+	*id = parsed
+	return nil
+}
+
+// Type returns the value type name shown in pflag usage.
+func (id *ID) Type() string {
+	return "region"
+}
@@ -0,0 +1,41 @@
+package region_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestID_Flag(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected region.ID
+	}{
+		{`--region=Одеська область`, region.Odesa},
+		{`--region=15`, region.Odesa},
+		{`--region=м. Київ`, region.KyivCity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			var id region.ID
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.Var(&id, "region", "region name or id")
+			require.NoError(t, flags.Parse([]string{test.arg}))
+			assert.Equal(t, test.expected, id)
+		})
+	}
+
+	var id region.ID
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(&id, "region", "region name or id")
+	require.Error(t, flags.Parse([]string{"--region=420"}))
+	assert.Equal(t, region.Invalid, id)
+	assert.Equal(t, "region", id.Type())
+}
@@ -0,0 +1,66 @@
+package region
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrOutsideUkraine is returned by FromCoordinates for points clearly outside Ukraine.
+var ErrOutsideUkraine = errors.New("region: point is outside Ukraine")
+
+type point struct {
+	lat, lon float64
+}
+
+// citiesRadiusKm is the approximate radius of the city regions,
+// which are matched before the surrounding oblasts.
+var citiesRadiusKm = map[ID]float64{
+	KyivCity:       20,
+	SevastopolCity: 15,
+}
+
+// bounding box of Ukraine
+const (
+	minLat, maxLat = 44.3, 52.4
+	minLon, maxLon = 22.1, 40.3
+)
+
+// FromCoordinates returns the region containing the point, approximated by the nearest region centroid.
+// Returns ErrOutsideUkraine if the point is clearly outside Ukraine.
+func FromCoordinates(lat, lon float64) (ID, error) {
+	if lat < minLat || lat > maxLat || lon < minLon || lon > maxLon || math.IsNaN(lat) || math.IsNaN(lon) {
+		return Invalid, ErrOutsideUkraine
+	}
+	p := point{lat, lon}
+	for id, radius := range citiesRadiusKm {
+		if distanceKm(p, centroid(id)) <= radius {
+			return id, nil
+		}
+	}
+
+	nearest, nearestDistance := Invalid, math.Inf(1)
+	for id := range metadataById {
+		if _, isCity := citiesRadiusKm[id]; isCity {
+			continue
+		}
+		if distance := distanceKm(p, centroid(id)); distance < nearestDistance {
+			nearest, nearestDistance = id, distance
+		}
+	}
+	return nearest, nil
+}
+
+// centroid returns the approximate geographic centroid of the region, see Metadata.
+func centroid(id ID) point {
+	metadata := metadataById[id]
+	return point{metadata.Lat, metadata.Lon}
+}
+
+// distanceKm returns the equirectangular approximation of the distance between points.
+func distanceKm(a, b point) float64 {
+	const earthRadiusKm = 6371
+	toRad := math.Pi / 180
+	x := (b.lon - a.lon) * toRad * math.Cos((a.lat+b.lat)/2*toRad)
+	y := (b.lat - a.lat) * toRad
+	return math.Hypot(x, y) * earthRadiusKm
+}
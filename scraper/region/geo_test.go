@@ -0,0 +1,53 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestFromCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		expected region.ID
+	}{
+		{"Kyiv", 50.4501, 30.5234, region.KyivCity},
+		{"Brovary", 50.5114, 30.7903, region.Kyiv},
+		{"Lviv", 49.8397, 24.0297, region.Lviv},
+		{"Kharkiv", 49.9935, 36.2304, region.Kharkiv},
+		{"Odesa", 46.4825, 30.7233, region.Odesa},
+		{"Dnipro", 48.4647, 35.0462, region.Dnipro},
+		{"Chernihiv", 51.4982, 31.2893, region.Chernihiv},
+		{"Uzhhorod", 48.6208, 22.2879, region.Zakarpattia},
+		{"Sevastopol", 44.6166, 33.5254, region.SevastopolCity},
+		{"Simferopol", 44.9521, 34.1024, region.Crimea},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := region.FromCoordinates(test.lat, test.lon)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, id)
+		})
+	}
+
+	outside := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"Warsaw", 52.2297, 21.0122},
+		{"Moscow", 55.7558, 37.6173},
+		{"Istanbul", 41.0082, 28.9784},
+		{"Null Island", 0, 0},
+	}
+	for _, test := range outside {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := region.FromCoordinates(test.lat, test.lon)
+			assert.ErrorIs(t, err, region.ErrOutsideUkraine)
+			assert.Equal(t, region.Invalid, id)
+		})
+	}
+}
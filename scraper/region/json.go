@@ -0,0 +1,47 @@
+package region
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// UnmarshalJSON decodes a region from a JSON number (15), a quoted number ("15"),
+// or a quoted region name ("Одеська область").
+// Returns an error if the value doesn't resolve to a valid region.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("region: %w", err)
+	}
+
+	var parsed ID
+	switch v := value.(type) {
+	case float64:
+		// out of range float to int conversion is implementation-defined, so check the range first
+		if v >= math.MinInt32 && v <= math.MaxInt32 && v == math.Trunc(v) {
+			parsed = ParseId(int(v))
+		}
+	case string:
+		parsed = ParseNameOrId(v)
+	}
+	if parsed == Invalid {
+		return fmt.Errorf("region: invalid region %s", data)
+	}
+	*id = parsed
+	return nil
+}
+
+// ParseNameOrId converts a numeric region id, e.g. "18", or a region name to its corresponding ID.
+// Returns Invalid ID if neither is found.
+func ParseNameOrId(s string) ID {
+	if id, err := strconv.Atoi(s); err == nil {
+		return ParseId(id)
+	}
+	return ParseName(s)
+}
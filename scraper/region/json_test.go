@@ -0,0 +1,81 @@
+package region_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestID_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected region.ID
+	}{
+		{`15`, region.Odesa},
+		{`"15"`, region.Odesa},
+		{`"Одеська область"`, region.Odesa},
+		{`"м. Київ"`, region.KyivCity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			var id region.ID
+			err := json.Unmarshal([]byte(test.json), &id)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, id)
+		})
+	}
+
+	invalid := []string{`0`, `420`, `15.5`, `"420"`, `"Курська Народна Республіка"`, `true`, `{}`, `[15]`}
+	for _, test := range invalid {
+		t.Run(test, func(t *testing.T) {
+			var id region.ID
+			err := json.Unmarshal([]byte(test), &id)
+			require.Error(t, err)
+			assert.Equal(t, region.Invalid, id)
+		})
+	}
+
+	t.Run("struct field", func(t *testing.T) {
+		var req struct {
+			Regions []region.ID `json:"regions"`
+		}
+		err := json.Unmarshal([]byte(`{"regions": [15, "26", "Львівська область"]}`), &req)
+		require.NoError(t, err)
+		assert.Equal(t, []region.ID{region.Odesa, region.KyivCity, region.Lviv}, req.Regions)
+	})
+}
+
+func FuzzRegionUnmarshal(f *testing.F) {
+	seeds := []string{
+		`15`, `"15"`, `"Одеська область"`, `"м. Київ"`, `"Одеській області."`, `null`,
+		`0`, `-1`, `420`, `15.5`, `1e300`, `-9223372036854775808`, `9223372036854775807`, `"420"`, `"-0"`,
+		`""`, `"Курська Народна Республіка"`, `true`, `{}`, `[15]`, `"`, `15"`, `"\ud800"`, "\"\xff\"",
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		id := region.Kyiv // must stay unchanged on error
+		if err := id.UnmarshalJSON(data); err != nil {
+			require.Equal(t, region.Kyiv, id)
+			return
+		}
+		if string(data) == "null" {
+			return
+		}
+		require.Equal(t, id, region.ParseId(int(id)), "decoded region must be valid")
+
+		// round trip
+		encoded, err := json.Marshal(id)
+		require.NoError(t, err)
+		var decoded region.ID
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		require.Equal(t, id, decoded)
+	})
+}
@@ -0,0 +1,265 @@
+package region
+
+// Metadata holds static reference data of a region.
+type Metadata struct {
+	Center             string  // administrative center
+	Lat                float64 // latitude of the approximate geographic centroid
+	Lon                float64 // longitude of the approximate geographic centroid
+	PopulationEstimate int     // official estimate as of 2022
+	AreaKm2            float64
+	EnglishName        string
+	Code               string // ISO 3166-2 code, e.g. "UA-51"
+}
+
+// metadataById is the single table of per-region static data.
+var metadataById = map[ID]Metadata{
+	Crimea: {
+		Center:             "Сімферополь",
+		Lat:                45.3,
+		Lon:                34.4,
+		PopulationEstimate: 1_902_000,
+		AreaKm2:            26081,
+		EnglishName:        "Autonomous Republic of Crimea",
+		Code:               "UA-43",
+	},
+	Vinnytsia: {
+		Center:             "Вінниця",
+		Lat:                49.0,
+		Lon:                28.6,
+		PopulationEstimate: 1_509_000,
+		AreaKm2:            26513,
+		EnglishName:        "Vinnytsia Oblast",
+		Code:               "UA-05",
+	},
+	Volyn: {
+		Center:             "Луцьк",
+		Lat:                51.0,
+		Lon:                25.0,
+		PopulationEstimate: 1_021_000,
+		AreaKm2:            20144,
+		EnglishName:        "Volyn Oblast",
+		Code:               "UA-07",
+	},
+	Dnipro: {
+		Center:             "Дніпро",
+		Lat:                48.3,
+		Lon:                35.3,
+		PopulationEstimate: 3_097_000,
+		AreaKm2:            31914,
+		EnglishName:        "Dnipropetrovsk Oblast",
+		Code:               "UA-12",
+	},
+	Donetsk: {
+		Center:             "Донецьк",
+		Lat:                48.0,
+		Lon:                37.8,
+		PopulationEstimate: 4_059_000,
+		AreaKm2:            26517,
+		EnglishName:        "Donetsk Oblast",
+		Code:               "UA-14",
+	},
+	Zhytomyr: {
+		Center:             "Житомир",
+		Lat:                50.5,
+		Lon:                28.4,
+		PopulationEstimate: 1_179_000,
+		AreaKm2:            29832,
+		EnglishName:        "Zhytomyr Oblast",
+		Code:               "UA-18",
+	},
+	Zakarpattia: {
+		Center:             "Ужгород",
+		Lat:                48.4,
+		Lon:                23.2,
+		PopulationEstimate: 1_245_000,
+		AreaKm2:            12777,
+		EnglishName:        "Zakarpattia Oblast",
+		Code:               "UA-21",
+	},
+	Zaporizhzhia: {
+		Center:             "Запоріжжя",
+		Lat:                47.2,
+		Lon:                35.6,
+		PopulationEstimate: 1_638_000,
+		AreaKm2:            27180,
+		EnglishName:        "Zaporizhzhia Oblast",
+		Code:               "UA-23",
+	},
+	IvanoFrankivsk: {
+		Center:             "Івано-Франківськ",
+		Lat:                48.7,
+		Lon:                24.5,
+		PopulationEstimate: 1_351_000,
+		AreaKm2:            13900,
+		EnglishName:        "Ivano-Frankivsk Oblast",
+		Code:               "UA-26",
+	},
+	Kyiv: {
+		Center:             "Київ",
+		Lat:                50.2,
+		Lon:                30.7,
+		PopulationEstimate: 1_788_000,
+		AreaKm2:            28131,
+		EnglishName:        "Kyiv Oblast",
+		Code:               "UA-32",
+	},
+	Kirovohrad: {
+		Center:             "Кропивницький",
+		Lat:                48.3,
+		Lon:                31.9,
+		PopulationEstimate: 903_000,
+		AreaKm2:            24588,
+		EnglishName:        "Kirovohrad Oblast",
+		Code:               "UA-35",
+	},
+	Luhansk: {
+		Center:             "Луганськ",
+		Lat:                48.8,
+		Lon:                39.1,
+		PopulationEstimate: 2_102_000,
+		AreaKm2:            26684,
+		EnglishName:        "Luhansk Oblast",
+		Code:               "UA-09",
+	},
+	Lviv: {
+		Center:             "Львів",
+		Lat:                49.7,
+		Lon:                23.9,
+		PopulationEstimate: 2_478_000,
+		AreaKm2:            21833,
+		EnglishName:        "Lviv Oblast",
+		Code:               "UA-46",
+	},
+	Mykolaiv: {
+		Center:             "Миколаїв",
+		Lat:                47.4,
+		Lon:                31.9,
+		PopulationEstimate: 1_091_000,
+		AreaKm2:            24598,
+		EnglishName:        "Mykolaiv Oblast",
+		Code:               "UA-48",
+	},
+	Odesa: {
+		Center:             "Одеса",
+		Lat:                46.6,
+		Lon:                30.0,
+		PopulationEstimate: 2_351_000,
+		AreaKm2:            33310,
+		EnglishName:        "Odesa Oblast",
+		Code:               "UA-51",
+	},
+	Poltava: {
+		Center:             "Полтава",
+		Lat:                49.6,
+		Lon:                33.8,
+		PopulationEstimate: 1_352_000,
+		AreaKm2:            28748,
+		EnglishName:        "Poltava Oblast",
+		Code:               "UA-53",
+	},
+	Rivne: {
+		Center:             "Рівне",
+		Lat:                51.0,
+		Lon:                26.3,
+		PopulationEstimate: 1_141_000,
+		AreaKm2:            20047,
+		EnglishName:        "Rivne Oblast",
+		Code:               "UA-56",
+	},
+	Sumy: {
+		Center:             "Суми",
+		Lat:                50.9,
+		Lon:                34.0,
+		PopulationEstimate: 1_035_000,
+		AreaKm2:            23834,
+		EnglishName:        "Sumy Oblast",
+		Code:               "UA-59",
+	},
+	Ternopil: {
+		Center:             "Тернопіль",
+		Lat:                49.4,
+		Lon:                25.6,
+		PopulationEstimate: 1_021_000,
+		AreaKm2:            13823,
+		EnglishName:        "Ternopil Oblast",
+		Code:               "UA-61",
+	},
+	Kharkiv: {
+		Center:             "Харків",
+		Lat:                49.6,
+		Lon:                36.5,
+		PopulationEstimate: 2_598_000,
+		AreaKm2:            31415,
+		EnglishName:        "Kharkiv Oblast",
+		Code:               "UA-63",
+	},
+	Kherson: {
+		Center:             "Херсон",
+		Lat:                46.5,
+		Lon:                33.7,
+		PopulationEstimate: 1_001_000,
+		AreaKm2:            28461,
+		EnglishName:        "Kherson Oblast",
+		Code:               "UA-65",
+	},
+	Khmelnytskyi: {
+		Center:             "Хмельницький",
+		Lat:                49.4,
+		Lon:                27.0,
+		PopulationEstimate: 1_228_000,
+		AreaKm2:            20645,
+		EnglishName:        "Khmelnytskyi Oblast",
+		Code:               "UA-68",
+	},
+	Cherkasy: {
+		Center:             "Черкаси",
+		Lat:                49.2,
+		Lon:                31.4,
+		PopulationEstimate: 1_160_000,
+		AreaKm2:            20900,
+		EnglishName:        "Cherkasy Oblast",
+		Code:               "UA-71",
+	},
+	Chernivtsi: {
+		Center:             "Чернівці",
+		Lat:                48.3,
+		Lon:                26.3,
+		PopulationEstimate: 890_000,
+		AreaKm2:            8097,
+		EnglishName:        "Chernivtsi Oblast",
+		Code:               "UA-77",
+	},
+	Chernihiv: {
+		Center:             "Чернігів",
+		Lat:                51.3,
+		Lon:                32.1,
+		PopulationEstimate: 959_000,
+		AreaKm2:            31865,
+		EnglishName:        "Chernihiv Oblast",
+		Code:               "UA-74",
+	},
+	KyivCity: {
+		Center:             "Київ",
+		Lat:                50.45,
+		Lon:                30.52,
+		PopulationEstimate: 2_952_000,
+		AreaKm2:            839,
+		EnglishName:        "Kyiv City",
+		Code:               "UA-30",
+	},
+	SevastopolCity: {
+		Center:             "Севастополь",
+		Lat:                44.6,
+		Lon:                33.55,
+		PopulationEstimate: 386_000,
+		AreaKm2:            864,
+		EnglishName:        "Sevastopol City",
+		Code:               "UA-40",
+	},
+}
+
+// Metadata returns static reference data of the region.
+// Returns zero Metadata if the ID is invalid.
+func (id ID) Metadata() Metadata {
+	return metadataById[id]
+}
@@ -0,0 +1,35 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestID_Metadata(t *testing.T) {
+	assert.Zero(t, region.Invalid.Metadata())
+	assert.Zero(t, region.ID(420).Metadata())
+
+	totalPopulation := 0
+	for id := range region.Iterator() {
+		metadata := id.Metadata()
+		assert.NotEmpty(t, metadata.Center, id.String())
+		assert.NotEmpty(t, metadata.EnglishName, id.String())
+		assert.True(t, metadata.Lat >= 44.3 && metadata.Lat <= 52.4, id.String())
+		assert.True(t, metadata.Lon >= 22.1 && metadata.Lon <= 40.3, id.String())
+		assert.Greater(t, metadata.PopulationEstimate, 300_000, id.String())
+		assert.Equal(t, id.AreaKm2(), metadata.AreaKm2, id.String())
+
+		found, err := region.FromCoordinates(metadata.Lat, metadata.Lon)
+		assert.NoError(t, err)
+		assert.Equal(t, id, found, "centroid must resolve to its region")
+		totalPopulation += metadata.PopulationEstimate
+	}
+	// Ukraine's population estimate as of 2022 is 41.2 million, 43.5 million including Crimea
+	assert.InEpsilon(t, 43_500_000, totalPopulation, 0.02)
+
+	assert.Equal(t, "Київ", region.KyivCity.Metadata().Center)
+	assert.Equal(t, "Odesa Oblast", region.Odesa.Metadata().EnglishName)
+}
@@ -2,6 +2,10 @@ package region
 
 import (
 	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Constants representing region IDs.
@@ -66,23 +70,118 @@ var namesById = map[ID]string{
 	27: "м. Севастополь",
 }
 
+// aliasesById holds alternate region names used in the channel posts.
+var aliasesById = map[ID][]string{
+	1:  {"АР Крим", "Крим", "Автономна республіка Крим"},
+	27: {"Севастополь"},
+}
+
+// genitivesById holds region names in the genitive case, e.g. "Одеської області".
+var genitivesById = map[ID]string{
+	1:  "Автономної Республіки Крим",
+	2:  "Вінницької області",
+	3:  "Волинської області",
+	4:  "Дніпропетровської області",
+	5:  "Донецької області",
+	6:  "Житомирської області",
+	7:  "Закарпатської області",
+	8:  "Запорізької області",
+	9:  "Івано-Франківської області",
+	10: "Київської області",
+	11: "Кіровоградської області",
+	12: "Луганської області",
+	13: "Львівської області",
+	14: "Миколаївської області",
+	15: "Одеської області",
+	16: "Полтавської області",
+	17: "Рівненської області",
+	18: "Сумської області",
+	19: "Тернопільської області",
+	20: "Харківської області",
+	21: "Херсонської області",
+	22: "Хмельницької області",
+	23: "Черкаської області",
+	24: "Чернівецької області",
+	25: "Чернігівської області",
+	26: "м. Києва",
+	27: "м. Севастополя",
+}
+
+// locativesById holds region names in the locative case, e.g. "(в) Одеській області".
+var locativesById = map[ID]string{
+	1:  "Автономній Республіці Крим",
+	2:  "Вінницькій області",
+	3:  "Волинській області",
+	4:  "Дніпропетровській області",
+	5:  "Донецькій області",
+	6:  "Житомирській області",
+	7:  "Закарпатській області",
+	8:  "Запорізькій області",
+	9:  "Івано-Франківській області",
+	10: "Київській області",
+	11: "Кіровоградській області",
+	12: "Луганській області",
+	13: "Львівській області",
+	14: "Миколаївській області",
+	15: "Одеській області",
+	16: "Полтавській області",
+	17: "Рівненській області",
+	18: "Сумській області",
+	19: "Тернопільській області",
+	20: "Харківській області",
+	21: "Херсонській області",
+	22: "Хмельницькій області",
+	23: "Черкаській області",
+	24: "Чернівецькій області",
+	25: "Чернігівській області",
+	26: "м. Києві",
+	27: "м. Севастополі",
+}
+
+// idsByName indexes all name forms and aliases, so ParseName is a single map lookup.
 var idsByName = make(map[string]ID, len(namesById))
 
 func init() {
 	for id, name := range namesById {
 		idsByName[name] = id
 	}
+	for id, name := range genitivesById {
+		idsByName[name] = id
+	}
+	for id, name := range locativesById {
+		idsByName[name] = id
+	}
+	for id, aliases := range aliasesById {
+		for _, alias := range aliases {
+			idsByName[alias] = id
+		}
+	}
 }
 
-// ParseName converts a region name to its corresponding ID.
+// ParseName converts a region name (in the nominative, genitive or locative case) or its alias to its corresponding ID.
+// Surrounding whitespace and a single trailing period are ignored, e.g. "Одеська область." as in clear messages.
+// The abbreviated "обл." suffix, with or without the period, is expanded to "область",
+// e.g. "Одеська обл." and "Одеська обл" (as the alert message regexp strips the period) resolve as "Одеська область".
 // Returns Invalid ID if the name is not found.
 func ParseName(name string) ID {
+	if id, exists := idsByName[name]; exists {
+		return id
+	}
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if base, ok := strings.CutSuffix(name, " "+oblastAbbr); ok {
+		name = base + " " + oblastWord
+	}
 	if id, exists := idsByName[name]; exists {
 		return id
 	}
 	return Invalid
 }
 
+const (
+	oblastWord = "область"
+	oblastAbbr = "обл"
+)
+
 // ParseId converts integer id to its corresponding ID.
 // Returns Invalid ID if the id is not found.
 func ParseId(id int) ID {
@@ -92,6 +191,17 @@ func ParseId(id int) ID {
 	return Invalid
 }
 
+// IsValidName reports whether the name or alias resolves to a region.
+func IsValidName(name string) bool {
+	return ParseName(name) != Invalid
+}
+
+// Names returns names of all regions, sorted lexicographically.
+// The returned slice is a fresh copy.
+func Names() []string {
+	return slices.Sorted(maps.Values(namesById))
+}
+
 // Count returns the number of regions in the package.
 func Count() int {
 	return len(namesById)
@@ -108,14 +218,59 @@ func Iterator() iter.Seq2[ID, string] {
 	}
 }
 
+// SortedIterator returns an iterator over region IDs and names in ascending ID order.
+func SortedIterator() iter.Seq2[ID, string] {
+	return func(yield func(ID, string) bool) {
+		for _, id := range slices.Sorted(maps.Keys(namesById)) {
+			if !yield(id, namesById[id]) {
+				return
+			}
+		}
+	}
+}
+
 // ID represents a unique identifier for a region.
 type ID int
 
 // String returns the name of the region corresponding to the ID.
-// Returns an empty string if the ID is invalid.
+// Returns "region(<id>)" if the ID is invalid, e.g. "region(42)".
 func (id ID) String() string {
 	if name, exists := namesById[id]; exists {
 		return name
 	}
-	return ""
+	return "region(" + strconv.Itoa(int(id)) + ")"
+}
+
+// Genitive returns the name of the region in the genitive case, e.g. "Одеської області" or "м. Києва".
+// Returns an empty string if the ID is invalid.
+func (id ID) Genitive() string {
+	return genitivesById[id]
+}
+
+// Locative returns the name of the region in the locative case, e.g. "Одеській області" or "м. Києві",
+// as in "Тривога в Одеській області".
+// Returns an empty string if the ID is invalid.
+func (id ID) Locative() string {
+	return locativesById[id]
+}
+
+// Oblast returns the oblast containing the region: Kyiv for KyivCity, Crimea for SevastopolCity,
+// and the region itself for oblasts. Returns Invalid if the ID is invalid.
+func (id ID) Oblast() ID {
+	switch id {
+	case KyivCity:
+		return Kyiv
+	case SevastopolCity:
+		return Crimea
+	}
+	if _, exists := namesById[id]; !exists {
+		return Invalid
+	}
+	return id
+}
+
+// AreaKm2 returns the area of the region in km².
+// Returns 0 if the ID is invalid.
+func (id ID) AreaKm2() float64 {
+	return metadataById[id].AreaKm2
 }
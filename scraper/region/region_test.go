@@ -1,7 +1,9 @@
 package region_test
 
 import (
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +18,11 @@ func TestParseName(t *testing.T) {
 	}{
 		{"м. Київ", region.KyivCity},
 		{"Автономна Республіка Крим", region.Crimea},
+		{"Автономна республіка Крим", region.Crimea},
+		{"АР Крим", region.Crimea},
+		{"Крим", region.Crimea},
+		{"м. Севастополь", region.SevastopolCity},
+		{"Севастополь", region.SevastopolCity},
 		{"Івано-Франківська область", region.IvanoFrankivsk},
 		{"Курська Народна Республіка", region.Invalid},
 	}
@@ -28,6 +35,82 @@ func TestParseName(t *testing.T) {
 	}
 }
 
+func TestParseName_Cases(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected region.ID
+	}{
+		{"Одеській області", region.Odesa},
+		{"Одеської області", region.Odesa},
+		{"Івано-Франківській області", region.IvanoFrankivsk},
+		{"Харківській області", region.Kharkiv},
+		{"Запорізької області", region.Zaporizhzhia},
+		{"Автономній Республіці Крим", region.Crimea},
+		{"м. Києві", region.KyivCity},
+		{"м. Севастополя", region.SevastopolCity},
+		{"Одеськiй областi", region.Invalid}, // latin i
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, region.ParseName(test.name))
+		})
+	}
+}
+
+func TestParseName_TrailingPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected region.ID
+	}{
+		{"Одеська область", region.Odesa},
+		{"Одеська область.", region.Odesa},
+		{"Одеська область. ", region.Odesa},
+		{" Одеська область .", region.Odesa},
+		{"м. Київ", region.KyivCity},
+		{"м. Київ.", region.KyivCity},
+		{"м. Києві.", region.KyivCity},
+		{"Одеська область..", region.Invalid}, // only a single period is ignored
+		{".", region.Invalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, region.ParseName(test.name))
+		})
+	}
+}
+
+func TestParseName_OblastAbbreviation(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected region.ID
+	}{
+		{"Одеська обл.", region.Odesa},
+		{"Харківська обл.", region.Kharkiv},
+		{"Івано-Франківська обл.", region.IvanoFrankivsk},
+		{"Закарпатська обл.", region.Zakarpattia},
+		{" Луганська обл. ", region.Luhansk},
+		{"Одеська обл", region.Odesa}, // the period is stripped by the alert message regexp
+		{"Одеська обл..", region.Invalid},
+		{"м. Київ обл.", region.Invalid},
+		{"обл.", region.Invalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, region.ParseName(test.name))
+		})
+	}
+
+	for id, name := range region.Iterator() {
+		if abbr, ok := strings.CutSuffix(name, "область"); ok {
+			assert.Equal(t, id, region.ParseName(abbr+"обл."), name)
+			assert.Equal(t, id, region.ParseName(abbr+"обл"), name)
+		}
+	}
+}
+
 func TestParseId(t *testing.T) {
 	tests := []struct {
 		id       int
@@ -48,3 +131,89 @@ func TestParseId(t *testing.T) {
 		})
 	}
 }
+
+func TestID_String(t *testing.T) {
+	assert.Equal(t, "Одеська область", region.Odesa.String())
+	assert.Equal(t, "м. Севастополь", region.SevastopolCity.String())
+	assert.Equal(t, "region(0)", region.Invalid.String())
+	assert.Equal(t, "region(42)", region.ID(42).String())
+	assert.Equal(t, "region(-1)", region.ID(-1).String())
+}
+
+func TestID_Genitive(t *testing.T) {
+	assert.Equal(t, "Одеської області", region.Odesa.Genitive())
+	assert.Equal(t, "Івано-Франківської області", region.IvanoFrankivsk.Genitive())
+	assert.Equal(t, "Автономної Республіки Крим", region.Crimea.Genitive())
+	assert.Equal(t, "м. Києва", region.KyivCity.Genitive())
+	assert.Equal(t, "м. Севастополя", region.SevastopolCity.Genitive())
+	assert.Empty(t, region.Invalid.Genitive())
+	assert.Empty(t, region.ID(42).Genitive())
+}
+
+func TestID_Locative(t *testing.T) {
+	assert.Equal(t, "Одеській області", region.Odesa.Locative())
+	assert.Equal(t, "Харківській області", region.Kharkiv.Locative())
+	assert.Equal(t, "Автономній Республіці Крим", region.Crimea.Locative())
+	assert.Equal(t, "у м. Києві", "у "+region.KyivCity.Locative())
+	assert.Equal(t, "м. Севастополі", region.SevastopolCity.Locative())
+	assert.Empty(t, region.Invalid.Locative())
+	assert.Empty(t, region.ID(42).Locative())
+
+	for id := range region.Iterator() {
+		assert.NotEmpty(t, id.Genitive(), id.String())
+		assert.NotEmpty(t, id.Locative(), id.String())
+		assert.Equal(t, id, region.ParseName(id.Locative()))
+	}
+}
+
+func TestID_AreaKm2(t *testing.T) {
+	assert.Zero(t, region.Invalid.AreaKm2())
+	assert.Zero(t, region.ID(420).AreaKm2())
+
+	total := 0.0
+	for id, _ := range region.Iterator() {
+		area := id.AreaKm2()
+		assert.Greater(t, area, 500.0, id.String())
+		assert.Less(t, area, 40_000.0, id.String())
+		total += area
+	}
+	// Ukraine's area is 603 628 km²
+	assert.InEpsilon(t, 603_628.0, total, 0.01)
+}
+
+func TestSortedIterator(t *testing.T) {
+	count := 0
+	prev := region.Invalid
+	for id, name := range region.SortedIterator() {
+		assert.Greater(t, id, prev)
+		assert.Equal(t, id.String(), name)
+		prev = id
+		count++
+	}
+	assert.Equal(t, region.Count(), count)
+}
+
+func TestNames(t *testing.T) {
+	names := region.Names()
+	assert.Len(t, names, region.Count())
+	assert.True(t, slices.IsSorted(names))
+	for _, name := range names {
+		assert.True(t, region.IsValidName(name), name)
+	}
+
+	// assert fresh slice is returned
+	names[0] = ""
+	assert.NotEmpty(t, region.Names()[0])
+
+	assert.True(t, region.IsValidName("АР Крим"))
+	assert.False(t, region.IsValidName("Курська Народна Республіка"))
+	assert.False(t, region.IsValidName(""))
+}
+
+func TestID_Oblast(t *testing.T) {
+	assert.Equal(t, region.Kyiv, region.KyivCity.Oblast())
+	assert.Equal(t, region.Crimea, region.SevastopolCity.Oblast())
+	assert.Equal(t, region.Odesa, region.Odesa.Oblast())
+	assert.Equal(t, region.Kyiv, region.Kyiv.Oblast())
+	assert.Equal(t, region.Invalid, region.ID(42).Oblast())
+}
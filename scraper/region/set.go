@@ -0,0 +1,40 @@
+package region
+
+import (
+	"slices"
+)
+
+// Union returns IDs present in a or b, sorted and deduplicated.
+func Union(a, b []ID) []ID {
+	union := make([]ID, 0, len(a)+len(b))
+	union = append(union, a...)
+	union = append(union, b...)
+	return sortedSet(union)
+}
+
+// Intersect returns IDs present in both a and b, sorted and deduplicated.
+func Intersect(a, b []ID) []ID {
+	intersection := make([]ID, 0, min(len(a), len(b)))
+	for _, id := range a {
+		if slices.Contains(b, id) {
+			intersection = append(intersection, id)
+		}
+	}
+	return sortedSet(intersection)
+}
+
+// Difference returns IDs present in a but not in b, sorted and deduplicated.
+func Difference(a, b []ID) []ID {
+	difference := make([]ID, 0, len(a))
+	for _, id := range a {
+		if !slices.Contains(b, id) {
+			difference = append(difference, id)
+		}
+	}
+	return sortedSet(difference)
+}
+
+func sortedSet(ids []ID) []ID {
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
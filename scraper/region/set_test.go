@@ -0,0 +1,60 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name                            string
+		a, b                            []region.ID
+		union, intersection, difference []region.ID
+	}{
+		{
+			name:         "overlap",
+			a:            []region.ID{region.Odesa, region.Kharkiv, region.Sumy},
+			b:            []region.ID{region.Sumy, region.Kyiv, region.Kharkiv},
+			union:        []region.ID{region.Kyiv, region.Odesa, region.Sumy, region.Kharkiv},
+			intersection: []region.ID{region.Sumy, region.Kharkiv},
+			difference:   []region.ID{region.Odesa},
+		},
+		{
+			name:         "disjoint",
+			a:            []region.ID{region.Lviv, region.Volyn},
+			b:            []region.ID{region.Kherson},
+			union:        []region.ID{region.Volyn, region.Lviv, region.Kherson},
+			intersection: []region.ID{},
+			difference:   []region.ID{region.Volyn, region.Lviv},
+		},
+		{
+			name:         "duplicates",
+			a:            []region.ID{region.Odesa, region.Odesa, region.Crimea},
+			b:            []region.ID{region.Crimea, region.Crimea},
+			union:        []region.ID{region.Crimea, region.Odesa},
+			intersection: []region.ID{region.Crimea},
+			difference:   []region.ID{region.Odesa},
+		},
+		{
+			name:         "empty",
+			a:            nil,
+			b:            []region.ID{region.Odesa},
+			union:        []region.ID{region.Odesa},
+			intersection: []region.ID{},
+			difference:   []region.ID{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := append([]region.ID(nil), test.a...)
+			assert.Equal(t, test.union, region.Union(test.a, test.b))
+			assert.Equal(t, test.intersection, region.Intersect(test.a, test.b))
+			assert.Equal(t, test.difference, region.Difference(test.a, test.b))
+			assert.Equal(t, a, test.a, "input must not be modified")
+		})
+	}
+}
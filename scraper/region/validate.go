@@ -0,0 +1,76 @@
+package region
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks that the per-region tables are complete and consistent:
+// every region has all name forms, a command and metadata, codes are unique,
+// and names, codes and centroids resolve back to the region.
+// Returns all found problems joined, or nil. Services may call it at startup to fail fast.
+func Validate() error {
+	var errs []error
+	fail := func(id ID, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("region: %d: %s", id, fmt.Sprintf(format, args...)))
+	}
+
+	for id, name := range namesById {
+		if ParseId(int(id)) != id {
+			fail(id, "id doesn't parse")
+		}
+		forms := []string{name, genitivesById[id], locativesById[id]}
+		forms = append(forms, aliasesById[id]...)
+		for _, form := range forms {
+			if form == "" {
+				fail(id, "missing name form")
+			} else if parsed := ParseName(form); parsed != id {
+				fail(id, "name %q parses to %d", form, parsed)
+			}
+		}
+		if id.Command() == InvalidCommand {
+			fail(id, "missing command")
+		}
+
+		metadata, ok := metadataById[id]
+		if !ok {
+			fail(id, "missing metadata")
+			continue
+		}
+		if metadata.Center == "" || metadata.EnglishName == "" || metadata.PopulationEstimate <= 0 || metadata.AreaKm2 <= 0 {
+			fail(id, "incomplete metadata")
+		}
+		if parsed := ParseCode(metadata.Code); parsed != id {
+			fail(id, "code %q parses to %d", metadata.Code, parsed)
+		}
+		if found, err := FromCoordinates(metadata.Lat, metadata.Lon); err != nil || found != id {
+			fail(id, "centroid resolves to %d", found)
+		}
+	}
+
+	extra := map[string]map[ID]string{
+		"genitive": genitivesById,
+		"locative": locativesById,
+	}
+	for table, values := range extra {
+		for id := range values {
+			if _, ok := namesById[id]; !ok {
+				fail(id, "unknown region in %s table", table)
+			}
+		}
+	}
+	for id := range commandsById {
+		if _, ok := namesById[id]; !ok {
+			fail(id, "unknown region in command table")
+		}
+	}
+	for id := range metadataById {
+		if _, ok := namesById[id]; !ok {
+			fail(id, "unknown region in metadata table")
+		}
+	}
+	if len(idsByCode) != len(metadataById) {
+		errs = append(errs, errors.New("region: codes are not unique"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,13 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, region.Validate())
+}
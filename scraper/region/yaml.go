@@ -0,0 +1,38 @@
+package region
+
+import (
+	"fmt"
+)
+
+// The methods below implement the yaml.Marshaler and the obsolete (func-based) yaml.Unmarshaler interfaces,
+// which gopkg.in/yaml.v3 still supports, so the package doesn't depend on a YAML library.
+
+// MarshalYAML encodes a region as its numeric id.
+func (id ID) MarshalYAML() (any, error) {
+	return int(id), nil
+}
+
+// UnmarshalYAML decodes a region from a YAML number (15), a quoted number ("15"),
+// or a region name (Одеська область), like UnmarshalJSON.
+// Returns an error if the value doesn't resolve to a valid region.
+func (id *ID) UnmarshalYAML(unmarshal func(any) error) error {
+	var value any
+	if err := unmarshal(&value); err != nil {
+		return fmt.Errorf("region: %w", err)
+	}
+
+	var parsed ID
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case int:
+		parsed = ParseId(v)
+	case string:
+		parsed = ParseNameOrId(v)
+	}
+	if parsed == Invalid {
+		return fmt.Errorf("region: invalid region %v", value)
+	}
+	*id = parsed
+	return nil
+}
@@ -0,0 +1,45 @@
+package region_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestID_UnmarshalYAML(t *testing.T) {
+	var config struct {
+		Regions []region.ID `yaml:"regions"`
+	}
+	err := yaml.Unmarshal([]byte(`
+regions:
+  - 15
+  - "26"
+  - Львівська область
+  - м. Київ
+`), &config)
+	require.NoError(t, err)
+	assert.Equal(t, []region.ID{region.Odesa, region.KyivCity, region.Lviv, region.KyivCity}, config.Regions)
+
+	invalid := []string{`0`, `420`, `15.5`, `"420"`, `Курська Народна Республіка`, `true`, `{}`, `[15]`}
+	for _, test := range invalid {
+		t.Run(test, func(t *testing.T) {
+			var id region.ID
+			require.Error(t, yaml.Unmarshal([]byte(test), &id))
+			assert.Equal(t, region.Invalid, id)
+		})
+	}
+}
+
+func TestID_MarshalYAML(t *testing.T) {
+	data, err := yaml.Marshal([]region.ID{region.Odesa, region.KyivCity})
+	require.NoError(t, err)
+	assert.Equal(t, "- 15\n- 26\n", string(data))
+
+	var ids []region.ID
+	require.NoError(t, yaml.Unmarshal(data, &ids))
+	assert.Equal(t, []region.ID{region.Odesa, region.KyivCity}, ids)
+}
@@ -0,0 +1,147 @@
+// Package scrapertest provides utilities for testing and load-testing TgScraper consumers.
+package scrapertest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zelenin/go-tdlib/client"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+const (
+	airAlertUaChannelID int64 = -1001766138888
+	chatTitle                 = "Повітряна тривога"
+)
+
+// Simulator is a scraper.TgClient emitting synthetic alert messages at a configured rate.
+// It has no history, so TgScraper relies on real-time updates only.
+//
+// The listeners' channels are sent to until Close is called, so Close must be called
+// before the listeners are closed, e.g. before TgScraper.Run returns.
+type Simulator struct {
+	regions  []region.ID
+	interval time.Duration
+	seed     uint64
+
+	lock      sync.Mutex
+	rnd       *rand.Rand
+	enabled   map[region.ID]bool
+	messageId int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var _ scraper.TgClient = (*Simulator)(nil)
+
+// NewSimulator creates a Simulator toggling alerts of random regions at rate messages per second.
+// Messages are generated from a fixed seed, so the sequence is reproducible, see WithSeed.
+// Panics if regions is empty or rate isn't positive.
+func NewSimulator(regions []region.ID, rate float64, opts ...func(*Simulator)) *Simulator {
+	if len(regions) == 0 {
+		panic("scrapertest: no regions to simulate")
+	}
+	if rate <= 0 {
+		panic(fmt.Sprintf("scrapertest: invalid rate %v, must be positive", rate))
+	}
+	simulator := &Simulator{
+		regions:  regions,
+		interval: time.Duration(float64(time.Second) / rate),
+		seed:     1,
+		enabled:  make(map[region.ID]bool, len(regions)),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(simulator)
+	}
+	simulator.rnd = rand.New(rand.NewPCG(simulator.seed, simulator.seed))
+	return simulator
+}
+
+// WithSeed sets the seed of the generated messages sequence. Default is 1.
+func WithSeed(seed uint64) func(*Simulator) {
+	return func(s *Simulator) {
+		s.seed = seed
+	}
+}
+
+// GetChatHistory always returns no messages.
+func (s *Simulator) GetChatHistory(*client.GetChatHistoryRequest) (*client.Messages, error) {
+	return &client.Messages{}, nil
+}
+
+// GetChat returns a chat with the requested id and the channel's title.
+func (s *Simulator) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: chatTitle}, nil
+}
+
+// GetListener returns a listener receiving synthetic messages until Close is called.
+func (s *Simulator) GetListener() *client.Listener {
+	updates := make(chan client.Type)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+			select {
+			case <-s.done:
+				return
+			case updates <- &client.UpdateNewMessage{Message: s.NextMessage()}:
+			}
+		}
+	}()
+	return &client.Listener{
+		Updates: updates,
+	}
+}
+
+// NextMessage generates the next message: an alert in a random region, or its clearance if the alert is active.
+func (s *Simulator) NextMessage() *client.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := s.regions[s.rnd.IntN(len(s.regions))]
+	enabled := !s.enabled[id]
+	s.enabled[id] = enabled
+	s.messageId++
+
+	now := time.Now().In(scraper.KyivLocation())
+	name := id.String()
+	hashtag := "#" + strings.NewReplacer(" ", "_", ".", "").Replace(name)
+	text := fmt.Sprintf("🟢 %s Відбій тривоги в %s.\n%s", now.Format("15:04"), name, hashtag)
+	if enabled {
+		text = fmt.Sprintf("🔴 %s Повітряна тривога в %s\n%s", now.Format("15:04"), name, hashtag)
+	}
+	return &client.Message{
+		Id:     s.messageId,
+		ChatId: airAlertUaChannelID,
+		Date:   int32(now.Unix()),
+		Content: &client.MessageText{
+			Text: &client.FormattedText{
+				Text: text,
+			},
+		},
+	}
+}
+
+// Close stops emitting messages and waits for the listeners' goroutines to exit,
+// after that the listeners can be closed.
+func (s *Simulator) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
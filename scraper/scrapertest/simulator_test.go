@@ -0,0 +1,95 @@
+package scrapertest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zelenin/go-tdlib/client"
+	"go.uber.org/goleak"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+	"github.com/mineroot/alert-data/scraper/scrapertest"
+)
+
+func TestSimulator(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	regions := []region.ID{region.Odesa, region.Kharkiv, region.KyivCity}
+	simulator := scrapertest.NewSimulator(regions, 500)
+	tgScraper := scraper.NewTgScraper(simulator)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	enabled := make(map[region.ID]bool)
+	for range 20 {
+		select {
+		case status := <-updates:
+			require.Contains(t, regions, status.Region)
+			require.NotEqual(t, enabled[status.Region], status.Enabled(), "alert must toggle")
+			enabled[status.Region] = status.Enabled()
+		case <-time.After(time.Second):
+			t.Fatal("no updates from simulator")
+		}
+	}
+
+	simulator.Close()
+	for id, expected := range enabled {
+		status, err := tgScraper.AlertData().GetByRegion(id)
+		require.NoError(t, err)
+		require.Equal(t, expected, status.Enabled())
+	}
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestSimulator_Close(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	simulator := scrapertest.NewSimulator([]region.ID{region.Odesa}, 500)
+	listener := simulator.GetListener()
+	require.NotNil(t, <-listener.Updates)
+
+	simulator.Close()
+	select {
+	case update := <-listener.Updates:
+		t.Fatalf("unexpected update after close: %v", update)
+	case <-time.After(20 * time.Millisecond):
+	}
+	listener.Close()
+
+	listener = simulator.GetListener()
+	select {
+	case update := <-listener.Updates:
+		t.Fatalf("unexpected update from a listener acquired after close: %v", update)
+	case <-time.After(20 * time.Millisecond):
+	}
+	listener.Close()
+}
+
+func TestSimulator_Seed(t *testing.T) {
+	regions := []region.ID{region.Odesa, region.Kharkiv, region.KyivCity, region.Lviv}
+	generate := func(seed uint64) []scraper.Status {
+		simulator := scrapertest.NewSimulator(regions, 1, scrapertest.WithSeed(seed))
+		var generated []scraper.Status
+		for range 50 {
+			message := simulator.NextMessage()
+			statuses, err := scraper.ParseAlertText(message.Content.(*client.MessageText).Text.Text, time.Unix(int64(message.Date), 0))
+			require.NoError(t, err)
+			require.Len(t, statuses, 1)
+			generated = append(generated, scraper.Status{Region: statuses[0].Region, Level: statuses[0].Level})
+		}
+		return generated
+	}
+
+	require.Equal(t, generate(42), generate(42))
+	require.NotEqual(t, generate(42), generate(43))
+}
@@ -0,0 +1,42 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// serverShutdownTimeout is the time given to in-flight requests to complete when RunServer is stopping.
+const serverShutdownTimeout = 5 * time.Second
+
+// RunServer creates a TgScraper with the given client and options, runs it and serves its alert data
+// on addr via NewHandler. It blocks until ctx is done or either the scraper or the server fails.
+func RunServer(ctx context.Context, client TgClient, addr string, opts ...func(*TgScraper)) error {
+	tgScraper := NewTgScraper(client, opts...)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           NewHandler(tgScraper.AlertData()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	g.Go(func() error {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+	return g.Wait()
+}
@@ -0,0 +1,50 @@
+package scraper_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestRunServer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- scraper.RunServer(ctx, newStubTgClient(), addr,
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		)
+	}()
+
+	httpClient := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	var statuses []scraper.Status
+	require.Eventually(t, func() bool {
+		resp, err := httpClient.Get(fmt.Sprintf("http://%s/alerts", addr))
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		statuses = nil
+		return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&statuses) == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, statuses, region.Count())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
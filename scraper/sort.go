@@ -0,0 +1,16 @@
+package scraper
+
+import (
+	"slices"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// SortStatusesByRegion sorts statuses in place by region ID in ascending order.
+func SortStatusesByRegion(statuses []Status) {
+	slices.SortFunc(statuses, compareStatusRegion)
+}
+
+func compareStatusRegion(a, b Status) int {
+	return region.CompareID(a.Region, b.Region)
+}
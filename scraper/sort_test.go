@@ -0,0 +1,27 @@
+package scraper_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+)
+
+func TestSortStatusesByRegion(t *testing.T) {
+	statuses := scraper.NewAlertData(nil).GetAll()
+	rnd := rand.New(rand.NewPCG(1, 2))
+	rnd.Shuffle(len(statuses), func(i, j int) {
+		statuses[i], statuses[j] = statuses[j], statuses[i]
+	})
+
+	scraper.SortStatusesByRegion(statuses)
+	require.True(t, slices.IsSortedFunc(statuses, func(a, b scraper.Status) int {
+		return int(a.Region) - int(b.Region)
+	}))
+	require.Equal(t, 0, int(testing.AllocsPerRun(10, func() {
+		scraper.SortStatusesByRegion(statuses)
+	})), "sorting must not allocate")
+}
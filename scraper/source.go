@@ -0,0 +1,21 @@
+package scraper
+
+import (
+	"context"
+)
+
+// Source is a transport providing alert statuses.
+// It's implemented by TgScraper and UkraineAlarmScraper.
+type Source interface {
+	// Run starts fetching statuses, blocking until ctx is done or a fatal error occurs.
+	Run(ctx context.Context) error
+	// AlertData returns current alert statuses.
+	AlertData() *AlertData
+	// UpdatesChan returns a channel with real-time status updates.
+	UpdatesChan() <-chan Status
+}
+
+var (
+	_ Source = (*TgScraper)(nil)
+	_ Source = (*UkraineAlarmScraper)(nil)
+)
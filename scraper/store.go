@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"maps"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// Store keeps region statuses behind AlertData, e.g. to share state between replicas via an external storage.
+// AlertData serializes its calls to the Store, but a Store shared by several AlertData must be safe for concurrent use.
+// Only the statuses are shared: the rest of AlertData state, i.e. logged transitions for StateAt,
+// previous statuses for LastTwo and ActiveSince, as well as applied messages of WithDeletedMessages,
+// is kept in memory of each replica.
+type Store interface {
+	// Get returns the status of the region and whether it exists.
+	Get(id region.ID) (Status, bool)
+	// CompareAndSet stores the status of status.Region if cond reports true for the currently stored one,
+	// exists is false if there is none. The region must not change between the cond call and storing,
+	// e.g. by another replica. Returns the previously stored status, whether it existed and whether status is stored.
+	CompareAndSet(status Status, cond func(current Status, exists bool) bool) (previous Status, exists, stored bool)
+	// Snapshot returns copies of all stored statuses.
+	Snapshot() map[region.ID]Status
+}
+
+// memoryStore is the default in-memory Store.
+type memoryStore map[region.ID]Status
+
+func newMemoryStore() memoryStore {
+	return make(memoryStore, region.Count())
+}
+
+func (s memoryStore) Get(id region.ID) (Status, bool) {
+	status, exists := s[id]
+	return status, exists
+}
+
+func (s memoryStore) CompareAndSet(status Status, cond func(Status, bool) bool) (Status, bool, bool) {
+	previous, exists := s[status.Region]
+	if !cond(previous, exists) {
+		return previous, exists, false
+	}
+	s[status.Region] = status
+	return previous, exists, true
+}
+
+func (s memoryStore) Snapshot() map[region.ID]Status {
+	return maps.Clone(s)
+}
@@ -0,0 +1,155 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+// SubscribeWithSnapshot atomically returns the current statuses and a channel receiving every status stored afterward,
+// so no change is missed between the snapshot and the first receive.
+// Statuses are queued for a slow receiver without limit. cancel must be called to release the subscription,
+// it closes the updates channel.
+func (r *AlertData) SubscribeWithSnapshot() (snapshot map[region.ID]Status, updates <-chan Status, cancel func()) {
+	sub := newStatusSubscriber()
+
+	r.lock.Lock()
+	snapshot = r.data.Snapshot()
+	r.subscribers[sub] = struct{}{}
+	r.lock.Unlock()
+
+	var once sync.Once
+	return snapshot, sub.updates, func() {
+		once.Do(func() {
+			r.lock.Lock()
+			delete(r.subscribers, sub)
+			r.lock.Unlock()
+			sub.close()
+		})
+	}
+}
+
+// Watch calls fn once for each region under alert (sorted by region ID), so the consumer syncs the initial state,
+// then for every status stored afterward, until ctx is cancelled. fn is called sequentially from the calling goroutine.
+// Returns ctx.Err().
+func (r *AlertData) Watch(ctx context.Context, fn func(Status)) error {
+	snapshot, updates, cancel := r.SubscribeWithSnapshot()
+	defer cancel()
+
+	active := make([]Status, 0, len(snapshot))
+	for _, status := range snapshot {
+		if status.Enabled() {
+			active = append(active, status)
+		}
+	}
+	SortStatusesByRegion(active)
+	for _, status := range active {
+		fn(status)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case status := <-updates:
+			fn(status)
+		}
+	}
+}
+
+// Changes returns a channel receiving every stored status, independent of other consumers and TgScraper.UpdatesChan().
+// The channel is buffered with bufSize; if the buffer is full, the status is dropped for this consumer,
+// so a slow consumer never blocks updating AlertData. cancel must be called to release the channel, it closes the channel.
+// Panics if bufSize is negative.
+func (r *AlertData) Changes(bufSize int) (changes <-chan Status, cancel func()) {
+	ch := make(chan Status, bufSize)
+	r.lock.Lock()
+	r.changes[ch] = struct{}{}
+	r.lock.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.lock.Lock()
+			delete(r.changes, ch)
+			close(ch) // under the lock, so publish never sends to the closed channel
+			r.lock.Unlock()
+		})
+	}
+}
+
+// publish queues the stored status to all subscribers and Changes consumers. Must be called with the write lock held.
+func (r *AlertData) publish(status Status) {
+	for sub := range r.subscribers {
+		sub.push(status)
+	}
+	for ch := range r.changes {
+		select {
+		case ch <- status:
+		default: // buffer is full, drop
+		}
+	}
+}
+
+// statusSubscriber forwards queued statuses to updates, so publishing never blocks on the receiver.
+type statusSubscriber struct {
+	updates chan Status
+
+	lock    sync.Mutex
+	queue   []Status
+	pending chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newStatusSubscriber() *statusSubscriber {
+	sub := &statusSubscriber{
+		updates: make(chan Status),
+		pending: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go sub.forward()
+	return sub
+}
+
+func (r *statusSubscriber) push(status Status) {
+	r.lock.Lock()
+	r.queue = append(r.queue, status)
+	r.lock.Unlock()
+	select {
+	case r.pending <- struct{}{}:
+	default: // forwarder is signaled already
+	}
+}
+
+func (r *statusSubscriber) forward() {
+	defer close(r.stopped)
+	defer close(r.updates)
+	for {
+		r.lock.Lock()
+		queue := r.queue
+		r.queue = nil
+		r.lock.Unlock()
+
+		for _, status := range queue {
+			select {
+			case <-r.done:
+				return
+			case r.updates <- status:
+			}
+		}
+
+		select {
+		case <-r.done:
+			return
+		case <-r.pending:
+		}
+	}
+}
+
+func (r *statusSubscriber) close() {
+	close(r.done)
+	<-r.stopped
+}
@@ -0,0 +1,114 @@
+package scraper_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+func TestAlertData_SubscribeWithSnapshot(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	alertData := scraper.NewAlertData(nil)
+	updatedAt := strToDate("2024-08-22 10:00:00")
+	const n = 100
+	alertData.Set(scraper.Status{Region: region.Odesa, UpdatedAt: updatedAt})
+
+	// writer races with the subscription, every update must be either in the snapshot or received
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range n {
+			alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.Level(i%2) * scraper.LevelFull, UpdatedAt: updatedAt.Add(time.Duration(i+1) * time.Minute)})
+		}
+	}()
+
+	snapshot, updates, cancel := alertData.SubscribeWithSnapshot()
+	defer cancel()
+	require.Len(t, snapshot, region.Count())
+	last := snapshot[region.Odesa].UpdatedAt
+	for !last.Equal(updatedAt.Add(n * time.Minute)) {
+		status := <-updates
+		require.Equal(t, last.Add(time.Minute), status.UpdatedAt, "update must not be missed")
+		last = status.UpdatedAt
+	}
+	wg.Wait()
+
+	cancel()
+	_, ok := <-updates
+	require.False(t, ok)
+	cancel() // idempotent
+}
+
+func TestAlertData_Changes(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	alertData := scraper.NewAlertData(nil)
+	fast, cancelFast := alertData.Changes(10)
+	slow, cancelSlow := alertData.Changes(1)
+
+	updatedAt := strToDate("2024-08-22 10:00:00")
+	statuses := []scraper.Status{
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt},
+		{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: updatedAt},
+		{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: updatedAt.Add(time.Minute)},
+	}
+	for _, status := range statuses {
+		alertData.Set(status) // must not block on the slow consumer
+	}
+
+	for _, expected := range statuses {
+		require.Equal(t, expected, <-fast)
+	}
+	require.Equal(t, statuses[0], <-slow, "statuses over the buffer size are dropped")
+	require.Empty(t, slow)
+
+	cancelSlow()
+	_, ok := <-slow
+	require.False(t, ok)
+	alertData.Set(scraper.Status{Region: region.Lviv, Level: scraper.LevelFull, UpdatedAt: updatedAt}) // must not panic
+	require.Equal(t, region.Lviv, (<-fast).Region, "other consumers are unaffected")
+
+	cancelFast()
+	cancelFast() // idempotent
+	_, ok = <-fast
+	require.False(t, ok)
+}
+
+func TestAlertData_Watch(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	alertData := scraper.NewAlertData(nil)
+	updatedAt := strToDate("2024-08-22 10:00:00")
+	alertData.Set(scraper.Status{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: updatedAt})
+
+	watched := make(chan scraper.Status)
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return alertData.Watch(ctx, func(status scraper.Status) {
+			watched <- status
+		})
+	})
+
+	// initial active regions, sorted by region ID
+	require.Equal(t, region.Crimea, (<-watched).Region)
+	require.Equal(t, region.Luhansk, (<-watched).Region)
+	require.Equal(t, region.Odesa, (<-watched).Region)
+
+	cleared := scraper.Status{Region: region.Odesa, Level: scraper.LevelNone, UpdatedAt: updatedAt.Add(time.Minute)}
+	alertData.Set(cleared)
+	require.Equal(t, cleared, <-watched)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
@@ -2,10 +2,12 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"regexp"
+	"log/slog"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zelenin/go-tdlib/client"
@@ -16,36 +18,136 @@ import (
 
 const airAlertUaChannelID int64 = -1001766138888
 
-var alertStatusRegexp = regexp.MustCompile(`(?m)^[🔴🟢🟡] (\d\d:\d\d) (Відбій тривоги|Повітряна тривога) в (.*?)\.?$`)
+const errorsChanSize = 16
+
+// listener is restarted with exponential backoff if it's closed or sends nil update
+const (
+	listenerRestartMinBackoff = 100 * time.Millisecond
+	listenerRestartMaxBackoff = 30 * time.Second
+)
+
+// failed WithOnChangeErr callback is retried with exponential backoff: 100ms, 200ms, 400ms, 800ms
+const (
+	onChangeMaxAttempts = 5
+	onChangeMinBackoff  = 100 * time.Millisecond
+)
+
+// ErrUnauthorized is returned from Run when the Telegram client loses its authorization,
+// e.g. the session was terminated. The client must be re-authorized before running a new scraper.
+var ErrUnauthorized = errors.New("telegram client is unauthorized")
+
+// ErrStopped is returned from Resync once Run has returned, as the scraper's channels are closed.
+var ErrStopped = errors.New("scraper is stopped")
+
+// ErrChatMismatch is returned from Run when the scraped chat's title differs from WithExpectedChatTitle.
+var ErrChatMismatch = errors.New("unexpected chat title")
 
 // TgScraper is a struct that handles scraping alert status updates from a Telegram channel.
 // It provides methods to run the scraper, retrieve alert data, and get real-time status updates.
 type TgScraper struct {
-	client               TgClient
-	historyFromDate      time.Time
-	updateDiscardTimeout time.Duration
+	client                 TgClient
+	historyFromDate        time.Time
+	updateDiscardTimeout   time.Duration
+	seedRegions            []region.ID
+	tracer                 Tracer
+	logger                 *slog.Logger
+	staleExpiry            time.Duration
+	messageBuffer          *messageBuffer
+	heartbeatInterval      time.Duration
+	onlyLocalHistory       bool
+	updateDedupWindow      time.Duration
+	streamHistory          bool
+	perRegionCooldown      time.Duration
+	skipHistoryParseErrors bool
+	onTransition           func(oldStatus, newStatus Status)
+	eventSink              func(Event)
+	onKeepalive            func(Status)
+	store                  Store
+	historyPageSize        int
+	appliedMessages        *appliedMessages
+	parseWorkers           int
+	secondPrecision        bool
+	expectedChatTitle      string
+	allowedForwardSenders  map[int64]struct{}
+	equalTimestampPolicy   EqualTimestampPolicy
+	futureTolerance        time.Duration
+	onChangeErr            func(Status) error
+	resyncPeriod           time.Duration
+	fullChannelPolicy      FullChannelPolicy
+	pinnedSummary          bool
+	regionFilter           map[region.ID]struct{}
+	historyConcurrency     int
+	orderedStream          bool
+	timeFallback           bool
 
-	once        sync.Once
-	historyDone chan struct{}
-	alertData   *AlertData
-	updates     chan Status
+	once            sync.Once
+	historyDone     chan struct{}
+	historyDoneOnce sync.Once
+	stopping        chan struct{} // closed once Run is returning, before stopLock is acquired by stop
+	stopLock        sync.RWMutex  // held by stop while closing the channels, read-held by Resync while sending
+	stopped         bool
+	alertData       *AlertData
+	updates         chan Status
+	errors          chan error
+	dropped         atomic.Uint64
 }
 
 // NewTgScraper creates a TgScraper with the given TgClient and optional settings.
 func NewTgScraper(client TgClient, opts ...func(*TgScraper)) *TgScraper {
 	scraper := &TgScraper{
-		client:               client,
-		historyFromDate:      time.Now().Add(-2 * 24 * time.Hour), // 2 days ago
-		updateDiscardTimeout: 0,
+		client:                 client,
+		historyFromDate:        time.Now().Add(-2 * 24 * time.Hour), // 2 days ago
+		updateDiscardTimeout:   0,
+		seedRegions:            nil,
+		tracer:                 noopTracer{},
+		logger:                 slog.Default(),
+		staleExpiry:            0,
+		messageBuffer:          nil,
+		heartbeatInterval:      0,
+		onlyLocalHistory:       false,
+		updateDedupWindow:      0,
+		streamHistory:          false,
+		perRegionCooldown:      0,
+		skipHistoryParseErrors: false,
+		onTransition:           nil,
+		eventSink:              nil,
+		onKeepalive:            nil,
+		store:                  newMemoryStore(),
+		historyPageSize:        1,
+		appliedMessages:        nil,
+		parseWorkers:           1,
+		secondPrecision:        false,
+		expectedChatTitle:      "",
+		allowedForwardSenders:  nil,
+		equalTimestampPolicy:   PreferLive,
+		futureTolerance:        DefaultFutureTolerance,
+		onChangeErr:            nil,
+		resyncPeriod:           time.Hour,
+		fullChannelPolicy:      Block,
+		pinnedSummary:          false,
+		regionFilter:           nil,
+		historyConcurrency:     1,
+		orderedStream:          false,
+		timeFallback:           false,
 
-		once:        sync.Once{},
-		historyDone: make(chan struct{}),
-		alertData:   newAlertData(),
-		updates:     nil,
+		once:            sync.Once{},
+		historyDone:     make(chan struct{}),
+		historyDoneOnce: sync.Once{},
+		stopping:        make(chan struct{}),
+		stopLock:        sync.RWMutex{},
+		stopped:         false,
+		alertData:       nil,
+		updates:         nil,
+		errors:          make(chan error, errorsChanSize),
 	}
 	for _, o := range opts {
 		o(scraper)
 	}
+	scraper.alertData = newAlertDataWithStore(scraper.seedRegions, scraper.store)
+	scraper.alertData.onTransition = scraper.onTransition
+	scraper.alertData.eventSink = scraper.eventSink
+	scraper.alertData.onKeepalive = scraper.onKeepalive
+	scraper.alertData.equalTimestampPolicy = scraper.equalTimestampPolicy
 	return scraper
 }
 
@@ -59,13 +161,334 @@ func WithHistoryFromDate(historyFromDate time.Time) func(*TgScraper) {
 
 // WithUpdateDiscardTimeout sets the timeout for discarding updates if UpdateChan() is full.
 // Default is 0, meaning updates won't be discarded, but the whole processing may be blocked if receiver is too slow.
+// Applies only to the Block policy, see WithFullChannelPolicy.
 func WithUpdateDiscardTimeout(timeout time.Duration) func(*TgScraper) {
 	return func(s *TgScraper) {
 		s.updateDiscardTimeout = timeout
 	}
 }
 
+// WithSeedRegions sets the regions AlertData is seeded with.
+// Default is all regions. Seeded regions start disabled, except Crimea and Luhansk which start enabled.
+// GetByRegion returns an error for regions that are not seeded.
+func WithSeedRegions(ids ...region.ID) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.seedRegions = ids
+	}
+}
+
+// WithTracer sets the Tracer used to create spans around history fetching and message parsing.
+// Default is a no-op tracer.
+func WithTracer(tracer Tracer) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.tracer = tracer
+	}
+}
+
+// WithLogger sets the logger used to report warnings.
+// Default is slog.Default().
+func WithLogger(logger *slog.Logger) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.logger = logger
+	}
+}
+
+// WithStaleExpiry enables a background sweep, run every expiry, that flags regions enabled
+// for longer than expiry with no update as Status.Stale and logs a warning.
+// Stale regions are not cleared, as a missed "Відбій тривоги" message can't be told apart from a long alert.
+// Default is 0, meaning the sweep is disabled.
+func WithStaleExpiry(expiry time.Duration) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.staleExpiry = expiry
+	}
+}
+
+// WithMessageBuffer enables retaining the last n raw messages received from the channel
+// in real-time, whether they were parsed or not. See TgScraper.RecentMessages.
+// Default is 0, meaning messages aren't retained.
+func WithMessageBuffer(n int) func(*TgScraper) {
+	return func(s *TgScraper) {
+		if n > 0 {
+			s.messageBuffer = newMessageBuffer(n)
+		} else {
+			s.messageBuffer = nil
+		}
+	}
+}
+
+// WithHeartbeat enables sending a heartbeat Status on UpdatesChan() after each interval without real updates,
+// so receivers can tell a quiet channel from a stuck scraper. See Status.IsHeartbeat.
+// Default is 0, meaning heartbeats are disabled.
+func WithHeartbeat(interval time.Duration) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.heartbeatInterval = interval
+	}
+}
+
+// WithOnlyLocalHistory sets whether history is fetched only from the local tdlib database, without network requests.
+// Useful for replaying history from a cached tdlib database offline.
+// Default is false.
+func WithOnlyLocalHistory(onlyLocal bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.onlyLocalHistory = onlyLocal
+	}
+}
+
+// WithPinnedSummary sets whether the state is bootstrapped from the channel's pinned summary of active alerts,
+// in which case only the history newer than the summary is fetched. The client must implement
+// GetChatPinnedMessage(*client.GetChatPinnedMessageRequest) (*client.Message, error), as the tdlib client does.
+// If it doesn't, or the pinned message isn't a summary, the whole history is fetched as usual.
+// Default is false.
+func WithPinnedSummary(pinnedSummary bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.pinnedSummary = pinnedSummary
+	}
+}
+
+// WithUpdateDedupWindow sets the window in which identical updates (same region and state) are sent
+// on UpdatesChan() only once, e.g. when the channel reposts the same message. The window is measured
+// between message dates. AlertData is updated regardless.
+// Default is 0, meaning updates aren't de-duplicated.
+func WithUpdateDedupWindow(window time.Duration) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.updateDedupWindow = window
+	}
+}
+
+// WithStreamingHistory sets whether history messages are processed newest-first as they're fetched,
+// instead of being collected and processed oldest-first. The resulting AlertData is the same,
+// but memory doesn't grow with the number of history messages: each fetched page (see WithHistoryPageSize)
+// is parsed and discarded before the next one is fetched.
+// Default is false.
+func WithStreamingHistory(stream bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.streamHistory = stream
+	}
+}
+
+// WithPerRegionCooldown limits updates sent on UpdatesChan() to one per region per cooldown.
+// Updates for the region during the cooldown are held back, and when it ends, the last of them is sent
+// if its state differs from the sent one. AlertData is updated immediately regardless.
+// Default is 0, meaning no cooldown.
+func WithPerRegionCooldown(cooldown time.Duration) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.perRegionCooldown = cooldown
+	}
+}
+
+// WithSkipHistoryParseErrors sets whether history messages that fail to parse are skipped,
+// reporting the error to Errors(), instead of failing Run.
+// Default is false.
+func WithSkipHistoryParseErrors(skip bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.skipHistoryParseErrors = skip
+	}
+}
+
+// WithOnTransition sets the callback called when a region's alert state changes, either from history or real-time.
+// oldStatus is the previously stored status, which is the seeded default for the first transition.
+// The callback is called synchronously, so it must not block.
+func WithOnTransition(onTransition func(oldStatus, newStatus Status)) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.onTransition = onTransition
+	}
+}
+
+// WithEventSink sets the sink receiving an Event for each alert level transition, e.g. for an append-only audit log.
+// The sink is called synchronously, so it must not block.
+func WithEventSink(sink func(Event)) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.eventSink = sink
+	}
+}
+
+// WithKeepalive sets the callback called when a repeated enabled message reconfirms the ongoing alert
+// at the same level: the stored UpdatedAt advances to the last confirmed time, while AlertData.ActiveSince is kept.
+// This distinguishes "alert reconfirmed" from "alert started", which is a transition, see WithOnTransition.
+// The callback is called synchronously, so it must not block.
+func WithKeepalive(onKeepalive func(Status)) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.onKeepalive = onKeepalive
+	}
+}
+
+// WithStore sets the Store backing AlertData, e.g. to share the state between several scrapers.
+// Default is in-memory store.
+func WithStore(store Store) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.store = store
+	}
+}
+
+// WithHistoryPageSize sets the max number of messages requested per GetChatHistory call. Default is 1,
+// as tdLib currently returns one message no matter what limit is.
+// Panics if n < 1.
+func WithHistoryPageSize(n int) func(*TgScraper) {
+	if n < 1 {
+		panic(fmt.Sprintf("scraper: invalid history page size %d, must be >= 1", n))
+	}
+	return func(s *TgScraper) {
+		s.historyPageSize = n
+	}
+}
+
+// WithDeletedMessages enables reverting the state when the channel deletes a message, e.g. an erroneous alert.
+// The region's state is re-derived from the remaining known messages of the region,
+// or reset to the seeded default if there are none. The re-derived status is sent to UpdatesChan.
+// Default is false.
+func WithDeletedMessages(handle bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		if handle {
+			s.appliedMessages = newAppliedMessages()
+		} else {
+			s.appliedMessages = nil
+		}
+	}
+}
+
+// WithParseWorkers sets the number of workers parsing real-time messages concurrently, e.g. during bursts of updates.
+// Parsed messages are still applied and emitted in the order they were received.
+// Default is 1, meaning messages are parsed one by one.
+// Panics if n < 1.
+func WithParseWorkers(n int) func(*TgScraper) {
+	if n < 1 {
+		panic(fmt.Sprintf("scraper: invalid parse workers number %d, must be >= 1", n))
+	}
+	return func(s *TgScraper) {
+		s.parseWorkers = n
+	}
+}
+
+// WithSecondPrecision sets whether parsed UpdatedAt includes the second of the message's date,
+// as the message text has minute precision only. This helps to order statuses within the same minute.
+// Default is false, meaning seconds are zero.
+func WithSecondPrecision(secondPrecision bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.secondPrecision = secondPrecision
+	}
+}
+
+// WithExpectedChatTitle makes Run verify the title of the scraped channel before fetching anything,
+// so a misconfigured channel id fails fast with ErrChatMismatch instead of silently scraping the wrong chat.
+// Default is "", meaning no verification.
+func WithExpectedChatTitle(title string) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.expectedChatTitle = title
+	}
+}
+
+// WithAllowForwardsFrom sets the trusted senders (user, chat or channel ids) whose forwarded history messages are parsed,
+// e.g. official alerts reposted by the channel. Forwards from other senders are skipped.
+// Default is none, so all forwarded history messages are skipped.
+func WithAllowForwardsFrom(senderIDs ...int64) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.allowedForwardSenders = make(map[int64]struct{}, len(senderIDs))
+		for _, id := range senderIDs {
+			s.allowedForwardSenders[id] = struct{}{}
+		}
+	}
+}
+
+// WithEqualTimestampPolicy sets how a status with the same UpdatedAt as the stored one is handled,
+// see EqualTimestampPolicy. Default is PreferLive.
+func WithEqualTimestampPolicy(policy EqualTimestampPolicy) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.equalTimestampPolicy = policy
+	}
+}
+
+// WithFutureTolerance sets how far the time in the message text may be after the message date
+// before it's considered to belong to the previous day, e.g. "23:59" in a message delayed until 00:01.
+// Default is DefaultFutureTolerance. Panics if tolerance is negative.
+func WithFutureTolerance(tolerance time.Duration) func(*TgScraper) {
+	if tolerance < 0 {
+		panic(fmt.Sprintf("scraper: invalid future tolerance %s, must be >= 0", tolerance))
+	}
+	return func(s *TgScraper) {
+		s.futureTolerance = tolerance
+	}
+}
+
+// WithOnChangeErr sets the callback called synchronously for each update sent on UpdatesChan(), except heartbeats,
+// e.g. to persist it. If the callback returns an error, it's retried up to 5 attempts in total
+// with exponential backoff starting at 100ms. If all attempts fail, the error is reported to Errors()
+// and the scraper moves on. Updates are not processed while the callback is retried.
+func WithOnChangeErr(onChange func(Status) error) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.onChangeErr = onChange
+	}
+}
+
+// WithResyncPeriod sets how far back Resync fetches the history.
+// Default is 1 hour. Panics if period isn't positive.
+func WithResyncPeriod(period time.Duration) func(*TgScraper) {
+	if period <= 0 {
+		panic(fmt.Sprintf("scraper: invalid resync period %s, must be > 0", period))
+	}
+	return func(s *TgScraper) {
+		s.resyncPeriod = period
+	}
+}
+
+// WithFullChannelPolicy sets what happens to an update when UpdatesChan() is full, see FullChannelPolicy.
+// Default is Block.
+func WithFullChannelPolicy(policy FullChannelPolicy) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.fullChannelPolicy = policy
+	}
+}
+
+// WithRegionFilter sets the only regions that are scraped: statuses of other regions are ignored,
+// neither stored nor sent to UpdatesChan(). AlertData is seeded with the filtered regions only, overriding WithSeedRegions.
+// Default is all regions.
+func WithRegionFilter(ids ...region.ID) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.seedRegions = ids
+		s.regionFilter = make(map[region.ID]struct{}, len(ids))
+		for _, id := range ids {
+			s.regionFilter[id] = struct{}{}
+		}
+	}
+}
+
+// WithHistoryConcurrency sets the number of workers fetching the history concurrently,
+// each scanning its own date partition of the history period, to cut the latency of sequential round-trips.
+// The client must implement GetChatMessageByDate to anchor the partitions, otherwise the history is fetched sequentially.
+// Ignored if WithStreamingHistory is set.
+// Default is 1, meaning the history is fetched sequentially.
+// Panics if n < 1.
+func WithHistoryConcurrency(n int) func(*TgScraper) {
+	if n < 1 {
+		panic(fmt.Sprintf("scraper: invalid history concurrency %d, must be >= 1", n))
+	}
+	return func(s *TgScraper) {
+		s.historyConcurrency = n
+	}
+}
+
+// WithOrderedStream sets whether UpdatesChan() is a single stream ordered by time: statuses of all history messages
+// are sent first, sorted by UpdatedAt, while real-time updates are held in the listener until the history is sent.
+// WithStreamingHistory is ignored, as the whole history is collected anyway.
+// Default is false, meaning only real-time updates are sent, concurrently with fetching the history.
+func WithOrderedStream(ordered bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.orderedStream = ordered
+	}
+}
+
+// WithTimeFallback sets whether a status message with a malformed time, e.g. "🔴 99:99 Повітряна тривога в ...",
+// is parsed with the message's date (truncated to the minute) as UpdatedAt, instead of failing to parse,
+// which would stop fetching the history (see WithSkipHistoryParseErrors).
+// Default is false.
+func WithTimeFallback(fallback bool) func(*TgScraper) {
+	return func(s *TgScraper) {
+		s.timeFallback = fallback
+	}
+}
+
 // Run starts the scraper.
+// If the client's listener is closed or sends nil update, a new listener is acquired with backoff.
+// Either Run or RunWithRecovery can be called once, subsequent calls return immediately.
 func (r *TgScraper) Run(ctx context.Context) error {
 	if r.client == nil {
 		panic("scraper: use scraper.NewTgScraper() to create *TgScraper instance")
@@ -84,7 +507,58 @@ func (r *TgScraper) Run(ctx context.Context) error {
 	return nil
 }
 
-// WaitForHistory blocks until historical data has been fetched.
+// Resync fetches the history for the last resync period (see WithResyncPeriod) and merges it into AlertData,
+// e.g. to recover transitions missed during a listener outage, without restarting the scraper.
+// Corrected statuses are sent on UpdatesChan(). It's safe to call concurrently with Run,
+// as outdated statuses never override newer ones. Returns ErrStopped once Run has returned.
+func (r *TgScraper) Resync(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stopping: // don't hold up stop by sending
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	messages, err := r.getMessagesForPeriod(ctx, time.Now().Add(-r.resyncPeriod))
+	if err != nil {
+		return fmt.Errorf("scraper: unable to resync: %w", err)
+	}
+	slices.Reverse(messages) // reverse slice so first message is most old
+	for _, message := range messages {
+		if err := r.resyncMessage(ctx, message); err != nil {
+			return fmt.Errorf("scraper: unable to resync: %w", err)
+		}
+	}
+	return nil
+}
+
+// resyncMessage merges statuses of the message into AlertData, unless the scraper is stopped.
+func (r *TgScraper) resyncMessage(ctx context.Context, message *client.Message) error {
+	r.stopLock.RLock()
+	defer r.stopLock.RUnlock()
+	if r.stopped {
+		return ErrStopped
+	}
+	statuses, err := r.parseHistoryMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if oldStatus, stored := r.alertData.set(&status); stored && isTransition(oldStatus, status) {
+			r.logger.Info("scraper: missed transition recovered by resync",
+				slog.String("region", status.Region.String()),
+				slog.String("level", status.Level.String()),
+			)
+			r.sendUpdate(ctx, status)
+		}
+	}
+	return nil
+}
+
+// WaitForHistory blocks until historical data has been fetched, or the scraper has stopped.
 func (r *TgScraper) WaitForHistory(ctx context.Context) error {
 	select {
 	case <-r.historyDone:
@@ -94,12 +568,42 @@ func (r *TgScraper) WaitForHistory(ctx context.Context) error {
 	}
 }
 
+// WaitForClear blocks until the raid alert in the region is disabled.
+// Returns immediately if it's disabled already.
+func (r *TgScraper) WaitForClear(ctx context.Context, id region.ID) error {
+	return r.alertData.waitFor(ctx, id, func(status Status) bool {
+		return !status.Enabled()
+	})
+}
+
+// RecentMessages returns raw messages retained by WithMessageBuffer, from the oldest to the newest.
+func (r *TgScraper) RecentMessages() []*client.Message {
+	if r.messageBuffer == nil {
+		return nil
+	}
+	return r.messageBuffer.all()
+}
+
+// Errors returns a channel with non-fatal errors, e.g. failure to parse a real-time update.
+// The scraper keeps running after such errors. Fatal errors are returned from Run.
+// If the channel is full, errors are dropped, see DroppedErrors.
+// The channel is closed when Run returns.
+func (r *TgScraper) Errors() <-chan error {
+	return r.errors
+}
+
+// DroppedErrors returns the number of non-fatal errors dropped because Errors() channel was full.
+func (r *TgScraper) DroppedErrors() uint64 {
+	return r.dropped.Load()
+}
+
 // AlertData returns current alert statuses.
 func (r *TgScraper) AlertData() *AlertData {
 	return r.alertData
 }
 
 // UpdatesChan returns a channel with real-time status updates.
+// If WithHeartbeat is set, the channel also receives heartbeats, see Status.IsHeartbeat.
 func (r *TgScraper) UpdatesChan() <-chan Status {
 	if r.updates == nil {
 		r.updates = make(chan Status, 1)
@@ -107,7 +611,56 @@ func (r *TgScraper) UpdatesChan() <-chan Status {
 	return r.updates
 }
 
+// UpdatesBatched returns a channel with real-time status updates collected into batches:
+// a batch is emitted window after its first update, so there are no empty batches.
+// Heartbeats are not batched. UpdatesBatched consumes UpdatesChan, so use only one of them.
+// On shutdown the pending batch is flushed and the channel is closed,
+// so the receiver must drain the channel until it's closed.
+func (r *TgScraper) UpdatesBatched(window time.Duration) <-chan []Status {
+	if window <= 0 {
+		panic("scraper: batch window must be positive")
+	}
+	updates := r.UpdatesChan()
+	batches := make(chan []Status)
+	go func() {
+		defer close(batches)
+		var batch []Status
+		var flush <-chan time.Time
+		for {
+			select {
+			case status, ok := <-updates:
+				if !ok {
+					if len(batch) > 0 {
+						batches <- batch
+					}
+					return
+				}
+				if status.IsHeartbeat() {
+					continue
+				}
+				if batch == nil {
+					flush = time.After(window)
+				}
+				batch = append(batch, status)
+			case <-flush:
+				batches <- batch
+				batch, flush = nil, nil
+			}
+		}
+	}()
+	return batches
+}
+
 func (r *TgScraper) run(ctx context.Context) error {
+	defer r.stop()
+	return r.runOnce(ctx)
+}
+
+// runOnce runs the scraper until the first fatal error. It can be called again after it returns.
+func (r *TgScraper) runOnce(ctx context.Context) error {
+	if err := r.verifyChat(); err != nil {
+		return err
+	}
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		return r.history(ctx)
@@ -115,69 +668,375 @@ func (r *TgScraper) run(ctx context.Context) error {
 	g.Go(func() error {
 		return r.listenUpdates(ctx)
 	})
+	if r.staleExpiry > 0 {
+		g.Go(func() error {
+			return r.sweepStale(ctx)
+		})
+	}
 
 	return g.Wait()
 }
 
-func (r *TgScraper) history(ctx context.Context) error {
-	defer close(r.historyDone)
-	messages, err := r.getMessagesForPeriod(ctx, r.historyFromDate)
+// stop closes the channels once the scraper has stopped.
+func (r *TgScraper) stop() {
+	close(r.stopping)
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+	r.stopped = true
+	r.closeUpdates()
+	close(r.errors)
+	r.closeHistoryDone()
+}
+
+func (r *TgScraper) closeHistoryDone() {
+	r.historyDoneOnce.Do(func() {
+		close(r.historyDone)
+	})
+}
+
+func (r *TgScraper) verifyChat() error {
+	if r.expectedChatTitle == "" {
+		return nil
+	}
+	chat, err := r.client.GetChat(&client.GetChatRequest{ChatId: airAlertUaChannelID})
+	if err != nil {
+		return fmt.Errorf("unable to get chat: %w", err)
+	}
+	if chat.Title != r.expectedChatTitle {
+		return fmt.Errorf("%w: expected %q, got %q", ErrChatMismatch, r.expectedChatTitle, chat.Title)
+	}
+	return nil
+}
+
+// reportError sends a non-fatal error to Errors() channel, or drops it if the channel is full.
+func (r *TgScraper) reportError(err error) {
+	select {
+	case r.errors <- err:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+func (r *TgScraper) sweepStale(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.historyDone:
+	}
+
+	ticker := time.NewTicker(r.staleExpiry)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			for _, status := range r.alertData.markStale(now, r.staleExpiry) {
+				r.logger.Warn("scraper: region alert is stale",
+					slog.String("region", status.Region.String()),
+					slog.Time("updated_at", status.UpdatedAt),
+				)
+			}
+		}
+	}
+}
+
+func (r *TgScraper) history(ctx context.Context) (err error) {
+	ctx, span := r.tracer.Start(ctx, "history")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			r.closeHistoryDone()
+		}
+		span.End()
+	}()
+
+	historyFromDate := r.historyFromDate
+	if r.pinnedSummary {
+		if summaryAt, ok := r.applyPinnedSummary(); ok && summaryAt.After(historyFromDate) {
+			historyFromDate = summaryAt
+		}
+	}
+
+	if r.streamHistory && !r.orderedStream {
+		return r.streamHistoryNewestFirst(ctx, historyFromDate)
+	}
+
+	messages, err := r.getMessagesForPeriod(ctx, historyFromDate)
 	if err != nil {
 		return err
 	}
+	span.SetAttribute("messages", len(messages))
 	slices.Reverse(messages) // reverse slice so first message is most old
+	var timeline []Status
 	for _, message := range messages {
-		status, err := r.parseMessage(message)
+		statuses, err := r.parseHistoryMessage(ctx, message)
 		if err != nil {
-			return fmt.Errorf("unable to scrape history: %w", err)
+			return err
 		}
-		if status == nil {
-			continue
+		for _, status := range statuses {
+			r.alertData.set(&status)
+		}
+		if r.orderedStream {
+			timeline = append(timeline, statuses...)
 		}
-		status.IsHistory = true
-
-		r.alertData.set(status)
 	}
 
+	slices.SortStableFunc(timeline, func(a, b Status) int {
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	})
+	for _, status := range timeline {
+		r.sendUpdate(ctx, status)
+	}
 	return nil
 }
 
-func (r *TgScraper) listenUpdates(ctx context.Context) error {
-	defer r.closeUpdates()
+// pinnedMessageClient is implemented by TgClient able to get the pinned message, see WithPinnedSummary.
+type pinnedMessageClient interface {
+	GetChatPinnedMessage(req *client.GetChatPinnedMessageRequest) (*client.Message, error)
+}
 
+// applyPinnedSummary stores the statuses of the pinned summary message.
+// Returns the summary's date and whether it's applied.
+func (r *TgScraper) applyPinnedSummary() (time.Time, bool) {
+	pinnedClient, ok := r.client.(pinnedMessageClient)
+	if !ok {
+		r.logger.Warn("scraper: client can't get pinned message, skipping summary")
+		return time.Time{}, false
+	}
+	message, err := pinnedClient.GetChatPinnedMessage(&client.GetChatPinnedMessageRequest{ChatId: airAlertUaChannelID})
+	if err != nil {
+		r.logger.Warn("scraper: unable to get pinned message, skipping summary", slog.Any("error", err))
+		return time.Time{}, false
+	}
+	text, _ := messageText(message.Content)
+	messageAt := time.Unix(int64(message.Date), 0)
+	statuses, ok := parseSummaryMessage(text, messageAt)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, status := range statuses {
+		if _, err := r.alertData.GetByRegion(status.Region); err != nil {
+			continue // not seeded, see WithSeedRegions
+		}
+		status.SourceMessageID = message.Id
+		r.alertData.set(&status)
+	}
+	return messageAt, true
+}
+
+func (r *TgScraper) listenUpdates(ctx context.Context) error {
 	listener := r.client.GetListener()
-	defer listener.Close()
+	listenerOpen := true
+	defer func() {
+		if listenerOpen {
+			listener.Close()
+		}
+	}()
+	if r.orderedStream {
+		// real-time updates are held in the listener until the history timeline is sent
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.historyDone:
+		}
+	}
+	restarts := 0
+	restartListener := func() error {
+		if listenerOpen {
+			listener.Close()
+			listenerOpen = false
+		}
+		backoff := min(listenerRestartMinBackoff<<min(restarts, 10), listenerRestartMaxBackoff)
+		restarts++
+		r.logger.Warn("scraper: restarting listener", slog.Duration("backoff", backoff))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		listener = r.client.GetListener()
+		listenerOpen = true
+		return nil
+	}
+
+	var heartbeat <-chan time.Time
+	resetHeartbeat := func() {}
+	if r.heartbeatInterval > 0 {
+		heartbeatTimer := time.NewTimer(r.heartbeatInterval)
+		defer heartbeatTimer.Stop()
+		heartbeat = heartbeatTimer.C
+		resetHeartbeat = func() {
+			heartbeatTimer.Reset(r.heartbeatInterval)
+		}
+	}
+
+	var cooldownEnded <-chan time.Time
+	cooldown := newRegionCooldown(r.perRegionCooldown)
+	cooldownTimer := time.NewTimer(r.perRegionCooldown)
+	cooldownTimer.Stop()
+	defer cooldownTimer.Stop()
+	armCooldown := func() {
+		if deadline, ok := cooldown.nextDeadline(); ok {
+			cooldownTimer.Reset(time.Until(deadline))
+			cooldownEnded = cooldownTimer.C
+		}
+	}
+
+	dedup := newUpdateDeduplicator(r.updateDedupWindow)
+	applyMessage := func(message *client.Message, statuses []Status, err error) {
+		if err != nil {
+			r.reportError(fmt.Errorf("scraper: unable to scrape update: %w", err))
+			return
+		}
+		r.trackAppliedMessage(message, statuses)
+		messageAt := time.Unix(int64(message.Date), 0)
+		for _, status := range statuses {
+			r.alertData.set(&status)
+			if dedup.isDuplicate(status, messageAt) {
+				continue
+			}
+			if !cooldown.allow(status, time.Now()) {
+				armCooldown()
+				continue
+			}
+			r.sendUpdate(ctx, status)
+			resetHeartbeat()
+		}
+	}
+
+	var pool *parsePool
+	var pending []<-chan parseResult // in the order of messages
+	if r.parseWorkers > 1 {
+		pool = r.newParsePool(ctx, r.parseWorkers)
+		defer pool.close()
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case update := <-listener.Updates:
+		case result := <-firstResult(pending):
+			pending = pending[1:]
+			applyMessage(result.message, result.statuses, result.err)
+		case now := <-cooldownEnded:
+			cooldownEnded = nil
+			for _, status := range cooldown.due(now) {
+				r.sendUpdate(ctx, status)
+				resetHeartbeat()
+			}
+			armCooldown()
+		case now := <-heartbeat:
+			r.sendUpdate(ctx, Status{
+				Region:    region.Invalid,
+				UpdatedAt: now.In(kyivLocation()),
+			})
+			resetHeartbeat()
+		case update, ok := <-listener.Updates:
+			if !ok {
+				listenerOpen = false // closed by the client
+			}
 			if update == nil {
-				return fmt.Errorf("received nil update")
+				if err := restartListener(); err != nil {
+					return err
+				}
+				break
+			}
+			restarts = 0
+			if update.GetType() == client.TypeUpdateAuthorizationState {
+				updateAuthorizationState, _ := update.(*client.UpdateAuthorizationState)
+				if updateAuthorizationState.AuthorizationState.AuthorizationStateType() != client.TypeAuthorizationStateReady {
+					return ErrUnauthorized
+				}
+				break
+			}
+			if update.GetType() == client.TypeUpdateDeleteMessages {
+				updateDeleteMessages, _ := update.(*client.UpdateDeleteMessages)
+				r.revertDeletedMessages(ctx, updateDeleteMessages)
+				break
 			}
-			// todo check this message from desired channel
 			if update.GetType() != client.TypeUpdateNewMessage {
 				break
 			}
 			updateNewMessage, _ := update.(*client.UpdateNewMessage)
-			status, err := r.parseMessage(updateNewMessage.Message)
-			if err != nil {
-				return fmt.Errorf("unable to scrape update: %w", err)
+			if updateNewMessage.Message.ChatId != airAlertUaChannelID {
+				break // skip messages from other chats
 			}
-			if status == nil {
+			if r.messageBuffer != nil {
+				r.messageBuffer.add(updateNewMessage.Message)
+			}
+			if pool != nil {
+				pending = append(pending, pool.submit(ctx, updateNewMessage.Message))
 				break
 			}
-			r.alertData.set(status)
-			r.sendUpdate(ctx, *status)
+			statuses, err := r.parseMessage(ctx, updateNewMessage.Message)
+			applyMessage(updateNewMessage.Message, statuses, err)
+		}
+	}
+}
+
+// trackAppliedMessage remembers statuses parsed from the message if WithDeletedMessages is set.
+func (r *TgScraper) trackAppliedMessage(message *client.Message, statuses []Status) {
+	if r.appliedMessages == nil {
+		return
+	}
+	for _, status := range statuses {
+		r.appliedMessages.add(message.Id, status)
+	}
+}
+
+// revertDeletedMessages re-derives the state of regions whose messages were deleted if WithDeletedMessages is set.
+func (r *TgScraper) revertDeletedMessages(ctx context.Context, update *client.UpdateDeleteMessages) {
+	if r.appliedMessages == nil || update.ChatId != airAlertUaChannelID || !update.IsPermanent || update.FromCache {
+		return
+	}
+	for _, status := range r.appliedMessages.remove(update.MessageIds) {
+		if !r.alertData.revert(status) {
+			continue // the deleted message didn't affect the region's state
 		}
+		r.logger.Info("scraper: message deleted, reverting region state",
+			slog.String("region", status.Region.String()),
+			slog.String("level", status.Level.String()),
+		)
+		r.sendUpdate(ctx, status)
 	}
 }
 
+// sendUpdate sends status to the updates channel according to the full channel policy.
+// With Block policy it blocks until the update is received, the update discard timeout expires or ctx is done.
+// Once ctx is done it never blocks, so a full channel can't hold up the shutdown.
+// The WithOnChangeErr callback is called before.
 func (r *TgScraper) sendUpdate(ctx context.Context, status Status) {
+	if r.onChangeErr != nil && !status.IsHeartbeat() {
+		r.callOnChange(ctx, status)
+	}
 	if r.updates == nil {
 		return
 	}
+	if ctx.Err() != nil {
+		return // shutting down, select below may pick the send even if ctx is done
+	}
+	switch r.fullChannelPolicy {
+	case DropNewest:
+		select {
+		case r.updates <- status:
+		default:
+		}
+		return
+	case DropOldest:
+		for {
+			select {
+			case r.updates <- status:
+				return
+			default:
+			}
+			select {
+			case <-r.updates: // evict the oldest, unless the consumer has just received it
+			default:
+			}
+		}
+	}
 	if r.updateDiscardTimeout != 0 {
 		var cancel context.CancelFunc = func() {}
 		ctx, cancel = context.WithTimeout(ctx, r.updateDiscardTimeout)
@@ -189,96 +1048,345 @@ func (r *TgScraper) sendUpdate(ctx context.Context, status Status) {
 	}
 }
 
+// callOnChange calls the WithOnChangeErr callback, retrying on error with backoff until it succeeds,
+// the attempts are exhausted or ctx is done.
+func (r *TgScraper) callOnChange(ctx context.Context, status Status) {
+	backoff := onChangeMinBackoff
+	for attempt := 1; ; attempt++ {
+		err := r.onChangeErr(status)
+		if err == nil {
+			return
+		}
+		if attempt == onChangeMaxAttempts {
+			r.reportError(fmt.Errorf("scraper: unable to deliver update of %s after %d attempts: %w", status.Region, attempt, err))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// parseHistoryMessage parses the history message, marking statuses as history.
+// If WithSkipHistoryParseErrors is set, parse errors are reported to Errors() and the message is skipped.
+func (r *TgScraper) parseHistoryMessage(ctx context.Context, message *client.Message) ([]Status, error) {
+	statuses, err := r.parseMessage(ctx, message)
+	if err != nil {
+		err = fmt.Errorf("unable to scrape history: %w", err)
+		if !r.skipHistoryParseErrors {
+			return nil, err
+		}
+		r.reportError(fmt.Errorf("scraper: %w", err))
+		return nil, nil
+	}
+	for i := range statuses {
+		statuses[i].IsHistory = true
+	}
+	r.trackAppliedMessage(message, statuses)
+	return statuses, nil
+}
+
+// streamHistoryNewestFirst processes history messages newest-first as they're fetched.
+func (r *TgScraper) streamHistoryNewestFirst(ctx context.Context, historyFromDate time.Time) error {
+	merger := newNewestFirstMerger()
+	err := r.walkHistory(ctx, historyFromDate, func(message *client.Message) error {
+		statuses, err := r.parseHistoryMessage(ctx, message)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			merger.add(status)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, status := range merger.result() {
+		r.alertData.set(&status)
+	}
+	return nil
+}
+
 // getMessagesForPeriod returns history for period (from now to now-period)
 func (r *TgScraper) getMessagesForPeriod(ctx context.Context, historyFromDate time.Time) ([]*client.Message, error) {
+	if r.historyConcurrency > 1 {
+		if byDateClient, ok := r.client.(messageByDateClient); ok {
+			return r.getMessagesConcurrently(ctx, byDateClient, historyFromDate, time.Now())
+		}
+		r.logger.Warn("scraper: client can't get message by date, fetching history sequentially")
+	}
+
+	messagesForPeriod := make([]*client.Message, 0, 200)
+	err := r.walkHistory(ctx, historyFromDate, func(message *client.Message) error {
+		messagesForPeriod = append(messagesForPeriod, message)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messagesForPeriod, nil
+}
+
+// messageByDateClient is implemented by TgClient able to get a message by date, see WithHistoryConcurrency.
+type messageByDateClient interface {
+	GetChatMessageByDate(req *client.GetChatMessageByDateRequest) (*client.Message, error)
+}
+
+// getMessagesConcurrently returns the same messages as getMessagesForPeriod, newest first,
+// fetching the period [historyFromDate, now) split into historyConcurrency partitions concurrently.
+// Each partition is walked from its newest message, found by date, to its start.
+func (r *TgScraper) getMessagesConcurrently(
+	ctx context.Context,
+	byDateClient messageByDateClient,
+	historyFromDate time.Time,
+	now time.Time,
+) ([]*client.Message, error) {
+	n := r.historyConcurrency
+	partitions := make([][]*client.Message, n) // oldest partition first
+	partition := now.Sub(historyFromDate) / time.Duration(n)
+	g, ctx := errgroup.WithContext(ctx)
+	for i := range n {
+		start := historyFromDate.Add(time.Duration(i) * partition)
+		g.Go(func() error {
+			var anchor *client.Message
+			if i < n-1 { // the newest partition is walked from the last message
+				end := start.Add(partition)
+				var err error
+				anchor, err = byDateClient.GetChatMessageByDate(&client.GetChatMessageByDateRequest{
+					ChatId: airAlertUaChannelID,
+					Date:   int32(end.Unix() - 1), // the last message sent before end
+				})
+				if err != nil {
+					return fmt.Errorf("unable to get message by date: %w", err)
+				}
+				if anchor == nil || anchor.Id == 0 {
+					return nil // no messages before end
+				}
+			}
+			return r.walkHistoryFrom(ctx, anchor, start, func(message *client.Message) error {
+				partitions[i] = append(partitions[i], message)
+				return nil
+			})
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
 	messagesForPeriod := make([]*client.Message, 0, 200)
+	for _, messages := range slices.Backward(partitions) {
+		messagesForPeriod = append(messagesForPeriod, messages...)
+	}
+	return messagesForPeriod, nil
+}
+
+// walkHistory calls yield for each text message newer than historyFromDate, from the newest to the oldest.
+func (r *TgScraper) walkHistory(ctx context.Context, historyFromDate time.Time, yield func(*client.Message) error) error {
+	return r.walkHistoryFrom(ctx, nil, historyFromDate, yield)
+}
+
+// walkHistoryFrom is walkHistory starting from the anchor message, or from the last message if anchor is nil.
+func (r *TgScraper) walkHistoryFrom(
+	ctx context.Context,
+	anchor *client.Message,
+	historyFromDate time.Time,
+	yield func(*client.Message) error,
+) error {
 	fromMessageId := int64(0)
+	var page []*client.Message
+	if anchor != nil {
+		page = []*client.Message{anchor}
+	}
 	for {
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-		messages, err := r.client.GetChatHistory(&client.GetChatHistoryRequest{
-			ChatId:        airAlertUaChannelID,
-			FromMessageId: fromMessageId,
-			Offset:        0,
-			Limit:         1, // tdLib always returns one message no matter what limit is
-			OnlyLocal:     false,
-		})
-		if err != nil {
-			return nil, err
-		}
-		if len(messages.Messages) == 0 {
-			break // no history left (should be unreachable in airAlertUaChannelID channel)
+			return ctx.Err()
 		}
-		message := messages.Messages[0]
-		messageDate := time.Unix(int64(message.Date), 0)
-		if messageDate.Before(historyFromDate) {
-			break // to old
+		if len(page) == 0 {
+			messages, err := r.getChatHistory(ctx, fromMessageId)
+			if err != nil {
+				return err
+			}
+			if len(messages.Messages) == 0 {
+				return nil // no history left (should be unreachable in airAlertUaChannelID channel)
+			}
+			page = messages.Messages
 		}
-		fromMessageId = message.Id
+		for _, message := range page {
+			fromMessageId = message.Id
+			messageDate := time.Unix(int64(message.Date), 0)
+			if messageDate.Before(historyFromDate) {
+				if message.IsPinned {
+					continue // pinned message may be out of date order, newer messages may follow
+				}
+				return nil // to old
+			}
 
-		if message.ForwardInfo != nil {
-			continue // skip forwarded posts
-		}
+			if message.ForwardInfo != nil && !r.isAllowedForward(message.ForwardInfo) {
+				continue // skip forwarded posts
+			}
 
-		if message.Content.MessageContentType() != client.TypeMessageText {
-			continue // skip not text messages
+			if _, ok := messageText(message.Content); !ok {
+				continue // skip messages without text
+			}
+			if err := yield(message); err != nil {
+				return err
+			}
 		}
-		messagesForPeriod = append(messagesForPeriod, message)
+		page = nil
 	}
-	return messagesForPeriod, nil
 }
 
-func (r *TgScraper) parseMessage(message *client.Message) (*Status, error) {
-	messageText, ok := message.Content.(*client.MessageText)
-	if !ok {
-		return nil, nil
+// isAllowedForward reports whether the forward's original sender is set by WithAllowForwardsFrom.
+func (r *TgScraper) isAllowedForward(forwardInfo *client.MessageForwardInfo) bool {
+	var senderId int64
+	switch origin := forwardInfo.Origin.(type) {
+	case *client.MessageOriginUser:
+		senderId = origin.SenderUserId
+	case *client.MessageOriginChat:
+		senderId = origin.SenderChatId
+	case *client.MessageOriginChannel:
+		senderId = origin.ChatId
+	default:
+		return false // hidden user
 	}
-	messageTextStr := messageText.Text.Text
+	_, allowed := r.allowedForwardSenders[senderId]
+	return allowed
+}
 
-	match := alertStatusRegexp.FindStringSubmatch(messageTextStr)
-	if len(match) < 3 {
-		return nil, nil
+func (r *TgScraper) getChatHistory(ctx context.Context, fromMessageId int64) (*client.Messages, error) {
+	_, span := r.tracer.Start(ctx, "GetChatHistory")
+	defer span.End()
+	span.SetAttribute("from_message_id", fromMessageId)
+
+	messages, err := r.client.GetChatHistory(&client.GetChatHistoryRequest{
+		ChatId:        airAlertUaChannelID,
+		FromMessageId: fromMessageId,
+		Offset:        0,
+		Limit:         int32(r.historyPageSize), // tdLib returns one message no matter what limit is, see WithHistoryPageSize
+		OnlyLocal:     r.onlyLocalHistory,
+	})
+	if err != nil {
+		span.RecordError(err)
 	}
+	return messages, err
+}
 
-	messageAt := time.Unix(int64(message.Date), 0)
-	timeOnly := match[1] + ":00"
-	parsedTime, err := time.Parse(time.TimeOnly, timeOnly)
+// parseMessage returns statuses of all regions listed in the message.
+func (r *TgScraper) parseMessage(ctx context.Context, message *client.Message) ([]Status, error) {
+	_, span := r.tracer.Start(ctx, "parseMessage")
+	defer span.End()
+	span.SetAttribute("message_id", message.Id)
+
+	statuses, err := r.parseMessageText(message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse time: %s: %w", timeOnly, err)
-	}
-	updatedAt := time.Date(
-		messageAt.Year(), messageAt.Month(), messageAt.Day(),
-		parsedTime.Hour(), parsedTime.Minute(),
-		0, 0, kyivLocation,
-	)
-	// in rare case when message arrives at 00:01 but parsed time is 23:59
-	if updatedAt.After(messageAt) {
-		updatedAt.Add(-24 * time.Hour)
-	}
-
-	raidStatusStr := match[2]
-	var raidEnabled bool
-	switch raidStatusStr {
-	case "Відбій тривоги":
-		raidEnabled = false
-	case "Повітряна тривога":
-		raidEnabled = true
-	default:
-		return nil, nil
+		span.RecordError(err)
 	}
+	return statuses, err
+}
 
-	regionStr := match[3]
-	regionId := region.ParseName(regionStr)
-	if regionId == region.Invalid {
-		return nil, nil
+func (r *TgScraper) parseMessageText(message *client.Message) ([]Status, error) {
+	extracted := NewTdlibMessage(message)
+	statuses, err := parseMessage(extracted, parseOptions{
+		futureTolerance: r.futureTolerance,
+		timeFallback:    r.timeFallback,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(r.regionFilter) > 0 {
+		statuses = slices.DeleteFunc(statuses, func(status Status) bool {
+			_, ok := r.regionFilter[status.Region]
+			return !ok
+		})
+	}
+	if !r.secondPrecision {
+		return statuses, nil
+	}
+	for i := range statuses {
+		statuses[i].UpdatedAt = statuses[i].UpdatedAt.Add(time.Duration(extracted.Date().Second()) * time.Second)
+	}
+	return statuses, nil
+}
+
+// newestFirstMerger merges statuses added newest-first into the same per-region result
+// AlertData.set would produce if they were added oldest-first.
+type newestFirstMerger struct {
+	settled  map[region.ID]Status // newest alert or clear status
+	warnings map[region.ID]Status // newest warning newer than the settled status
+}
+
+func newNewestFirstMerger() *newestFirstMerger {
+	return &newestFirstMerger{
+		settled:  make(map[region.ID]Status),
+		warnings: make(map[region.ID]Status),
+	}
+}
+
+func (r *newestFirstMerger) add(status Status) {
+	if _, settled := r.settled[status.Region]; settled {
+		return // older than the settled status
+	}
+	if status.Level == LevelWarning {
+		if _, exists := r.warnings[status.Region]; !exists {
+			r.warnings[status.Region] = status
+		}
+		return
+	}
+	r.settled[status.Region] = status
+}
+
+func (r *newestFirstMerger) result() []Status {
+	statuses := make([]Status, 0, len(r.settled)+len(r.warnings))
+	for id, status := range r.settled {
+		warning, warned := r.warnings[id]
+		if warned && !status.Enabled() {
+			// warning after clear takes over, while warning after alert doesn't override it
+			status = warning
+		}
+		statuses = append(statuses, status)
+	}
+	for id, warning := range r.warnings {
+		if _, settled := r.settled[id]; !settled {
+			statuses = append(statuses, warning)
+		}
 	}
+	return statuses
+}
 
-	return &Status{
-		Region:    regionId,
-		Enabled:   raidEnabled,
-		UpdatedAt: updatedAt,
-	}, nil
+// updateDeduplicator detects identical updates within a window.
+type updateDeduplicator struct {
+	window time.Duration
+	seen   map[updateDedupKey]time.Time
+}
+
+type updateDedupKey struct {
+	region region.ID
+	level  Level
+}
+
+func newUpdateDeduplicator(window time.Duration) *updateDeduplicator {
+	return &updateDeduplicator{
+		window: window,
+		seen:   make(map[updateDedupKey]time.Time),
+	}
+}
+
+// isDuplicate reports whether an identical status was seen less than window before messageAt.
+func (r *updateDeduplicator) isDuplicate(status Status, messageAt time.Time) bool {
+	if r.window <= 0 {
+		return false
+	}
+	key := updateDedupKey{region: status.Region, level: status.Level}
+	seenAt, seen := r.seen[key]
+	if seen && messageAt.Sub(seenAt) < r.window {
+		return true
+	}
+	r.seen[key] = messageAt
+	return false
 }
 
 func (r *TgScraper) closeUpdates() {
@@ -1,9 +1,15 @@
 package scraper_test
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,6 +22,8 @@ import (
 	"github.com/mineroot/alert-data/scraper/region"
 )
 
+const airAlertUaChannelID int64 = -1001766138888
+
 var kyivLocation *time.Location
 
 func init() {
@@ -57,8 +65,9 @@ func TestTgScraper(t *testing.T) {
 	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
 	require.Equal(t, scraper.Status{
 		Region:    region.Odesa,
-		Enabled:   true,
+		Level:     scraper.LevelFull,
 		UpdatedAt: strToDate("2024-08-21 02:15:00"),
+		Marker:    '🔴',
 		IsHistory: true,
 	}, status)
 
@@ -66,7 +75,7 @@ func TestTgScraper(t *testing.T) {
 	status, _ = tgScraper.AlertData().GetByRegion(region.Crimea)
 	require.Equal(t, scraper.Status{
 		Region:    region.Crimea,
-		Enabled:   true,
+		Level:     scraper.LevelFull,
 		UpdatedAt: strToDate("2022-12-11 00:22:00"),
 		IsHistory: true,
 	}, status)
@@ -74,17 +83,19 @@ func TestTgScraper(t *testing.T) {
 	status, _ = tgScraper.AlertData().GetByRegion(region.Luhansk)
 	require.Equal(t, scraper.Status{
 		Region:    region.Luhansk,
-		Enabled:   true,
+		Level:     scraper.LevelFull,
 		UpdatedAt: strToDate("2022-04-04 19:45:00"),
 		IsHistory: true,
 	}, status)
 
 	// assert parsed "🔴 08:39 Повітряна тривога в м. Київ ..."
 	status = <-updates
+	require.False(t, status.IsHeartbeat())
 	require.Equal(t, scraper.Status{
 		Region:    region.KyivCity,
-		Enabled:   true,
+		Level:     scraper.LevelFull,
 		UpdatedAt: strToDate("2024-08-22 08:39:00"),
+		Marker:    '🔴',
 		IsHistory: false,
 	}, status)
 
@@ -92,8 +103,9 @@ func TestTgScraper(t *testing.T) {
 	status = <-updates
 	require.Equal(t, scraper.Status{
 		Region:    region.KyivCity,
-		Enabled:   false,
+		Level:     scraper.LevelNone,
 		UpdatedAt: strToDate("2024-08-22 10:06:00"),
+		Marker:    '🟢',
 		IsHistory: false,
 	}, status)
 
@@ -111,70 +123,2124 @@ func TestTgScraper(t *testing.T) {
 	require.NoError(t, err)
 }
 
-type stubTgClient struct {
-	history chan *client.Message
-	updates chan client.Type
+func TestTgScraper_WithSeedRegions(t *testing.T) {
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClient(),
+		scraper.WithSeedRegions(region.Odesa, region.Crimea),
+	)
+	alertData := tgScraper.AlertData()
+
+	require.Len(t, alertData.GetAll(), 2)
+
+	status, err := alertData.GetByRegion(region.Odesa)
+	require.NoError(t, err)
+	require.False(t, status.Enabled())
+
+	// hardcoded long-running alert is applied to seeded Crimea
+	status, err = alertData.GetByRegion(region.Crimea)
+	require.NoError(t, err)
+	require.True(t, status.Enabled())
+
+	// unseeded regions error, including hardcoded Luhansk
+	_, err = alertData.GetByRegion(region.KyivCity)
+	require.Error(t, err)
+	_, err = alertData.GetByRegion(region.Luhansk)
+	require.Error(t, err)
 }
 
-func newStubTgClient() *stubTgClient {
-	historyMessages := []*client.Message{
-		createTestMessage(
-			"🟢 19:46 Відбій тривоги в Одеська область.\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
-			strToDate("2024-08-19 19:46:52"),
+func TestTgScraper_WithTracer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tracer := &recordingTracer{}
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClient(),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithTracer(tracer),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	err := tgScraper.WaitForHistory(ctx)
+	require.NoError(t, err)
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	require.Contains(t, tracer.names(), "history")
+	require.Contains(t, tracer.names(), "GetChatHistory")
+	require.Contains(t, tracer.names(), "parseMessage")
+}
+
+func TestTgScraper_Unauthorized(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateAuthorizationState{AuthorizationState: &client.AuthorizationStateClosed{}},
+			},
 		),
-		createTestMessage(
-			"🔴 02:15 Повітряна тривога в Одеська область\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
-			strToDate("2024-08-21 02:15:19"),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+
+	err := tgScraper.Run(context.Background())
+	require.ErrorIs(t, err, scraper.ErrUnauthorized)
+}
+
+func TestTgScraper_MultiRegionMessage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{
+				createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+				createTestMessage(
+					"🔴 02:15 Повітряна тривога в Одеська область\n"+
+						"🔴 02:16 Повітряна тривога в Миколаївська область\n"+
+						"Слідкуйте за подальшими повідомленнями.",
+					strToDate("2024-08-21 02:16:19"),
+				),
+			},
+			nil,
 		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.Equal(t, scraper.Status{
+		Region:    region.Odesa,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-21 02:15:00"),
+		Marker:    '🔴',
+		IsHistory: true,
+	}, status)
+
+	status, _ = tgScraper.AlertData().GetByRegion(region.Mykolaiv)
+	require.Equal(t, scraper.Status{
+		Region:    region.Mykolaiv,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-21 02:16:00"),
+		Marker:    '🔴',
+		IsHistory: true,
+	}, status)
+}
+
+func TestTgScraper_AbbreviatedRegion(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{
+				createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+				createTestMessage(
+					"🔴 02:15 Повітряна тривога в Харківська обл.\n"+
+						"🔴 02:16 Повітряна тривога в Полтавська обл\n"+
+						"Слідкуйте за подальшими повідомленнями.",
+					strToDate("2024-08-21 02:16:19"),
+				),
+			},
+			nil,
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	for _, id := range []region.ID{region.Kharkiv, region.Poltava} {
+		status, err := tgScraper.AlertData().GetByRegion(id)
+		require.NoError(t, err)
+		require.Equal(t, scraper.LevelFull, status.Level, id.String())
 	}
-	history := make(chan *client.Message, len(historyMessages))
-	defer close(history)
-	slices.Reverse(historyMessages) // newer messages first
-	for _, message := range historyMessages {
-		history <- message
-	}
+	status, _ := tgScraper.AlertData().GetByRegion(region.Kharkiv)
+	require.Equal(t, strToDate("2024-08-21 02:15:00"), status.UpdatedAt)
+}
 
-	updatesMessages := []*client.Message{
-		createTestMessage(
-			"🔴 08:39 Повітряна тривога в м. Київ\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
-			strToDate("2024-08-22 08:40:01"),
+func TestTgScraper_WithStaleExpiry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClient(),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithStaleExpiry(10*time.Millisecond),
+		scraper.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	// Odesa alert from history has been enabled since 2024-08-21
+	require.Eventually(t, func() bool {
+		status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+		return status.Stale
+	}, time.Second, 10*time.Millisecond)
+
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled(), "stale region must not be cleared")
+
+	// long-running alerts are never stale
+	status, _ = tgScraper.AlertData().GetByRegion(region.Crimea)
+	require.False(t, status.Stale)
+	// disabled regions are never stale
+	status, _ = tgScraper.AlertData().GetByRegion(region.Lviv)
+	require.False(t, status.Stale)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WaitForClear(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(newStubTgClient())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.Run("already clear", func(t *testing.T) {
+		err := tgScraper.WaitForClear(ctx, region.Lviv)
+		require.NoError(t, err)
+	})
+
+	t.Run("later clear", func(t *testing.T) {
+		tgScraper.AlertData().Set(scraper.Status{
+			Region:    region.Odesa,
+			Level:     scraper.LevelFull,
+			UpdatedAt: strToDate("2024-08-21 02:15:00"),
+			Marker:    '🔴',
+		})
+		done := make(chan error)
+		go func() {
+			done <- tgScraper.WaitForClear(ctx, region.Odesa)
+		}()
+
+		select {
+		case <-done:
+			require.Fail(t, "WaitForClear returned while alert is enabled")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		tgScraper.AlertData().Set(scraper.Status{
+			Region:    region.Odesa,
+			Level:     scraper.LevelNone,
+			UpdatedAt: strToDate("2024-08-21 03:15:00"),
+			Marker:    '🟢',
+		})
+		require.NoError(t, <-done)
+	})
+
+	t.Run("ctx cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := tgScraper.WaitForClear(ctx, region.Crimea)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("invalid region", func(t *testing.T) {
+		err := tgScraper.WaitForClear(ctx, region.Invalid)
+		require.Error(t, err)
+	})
+}
+
+func TestTgScraper_WithMessageBuffer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	otherChatMessage := createTestMessage(
+		"🔴 08:38 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 08:38:01"),
+	)
+	otherChatMessage.ChatId = 42
+	enabledMessage := createTestMessage(
+		"🔴 08:39 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 08:40:01"),
+	)
+	unrelatedMessage := createTestMessage("Слідкуйте за подальшими повідомленнями.", strToDate("2024-08-22 09:00:00"))
+	disabledMessage := createTestMessage(
+		"🟢 10:06 Відбій тривоги в м. Київ.",
+		strToDate("2024-08-22 10:06:43"),
+	)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: otherChatMessage},
+				&client.UpdateNewMessage{Message: enabledMessage},
+				&client.UpdateNewMessage{Message: unrelatedMessage},
+				&client.UpdateNewMessage{Message: disabledMessage},
+			},
 		),
-		createTestMessage(
-			"🟢 10:06 Відбій тривоги в м. Київ.\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
-			strToDate("2024-08-22 10:06:43"),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithMessageBuffer(2),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	// wait until the last message is processed
+	<-updates
+	status := <-updates
+	require.False(t, status.Enabled())
+
+	require.Equal(t, []*client.Message{unrelatedMessage, disabledMessage}, tgScraper.RecentMessages())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithHeartbeat(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			nil,
 		),
-	}
-	updates := make(chan client.Type, len(updatesMessages))
-	//defer close(updates)
-	for _, message := range updatesMessages {
-		updates <- &client.UpdateNewMessage{Message: message}
-	}
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithHeartbeat(10*time.Millisecond),
+	)
+	updates := tgScraper.UpdatesChan()
 
-	return &stubTgClient{
-		history: history,
-		updates: updates,
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	for range 2 {
+		status := <-updates
+		require.True(t, status.IsHeartbeat())
+		require.False(t, status.UpdatedAt.IsZero())
 	}
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
 }
 
-func (r *stubTgClient) GetListener() *client.Listener {
-	return &client.Listener{
-		Updates: r.updates,
+func TestTgScraper_WithOnlyLocalHistory(t *testing.T) {
+	for _, onlyLocal := range []bool{false, true} {
+		t.Run(fmt.Sprint(onlyLocal), func(t *testing.T) {
+			defer goleak.VerifyNone(t)
+
+			stub := newStubTgClient()
+			tgScraper := scraper.NewTgScraper(
+				stub,
+				scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+				scraper.WithOnlyLocalHistory(onlyLocal),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() error {
+				return tgScraper.Run(ctx)
+			})
+			require.NoError(t, tgScraper.WaitForHistory(ctx))
+			cancel()
+			require.ErrorIs(t, g.Wait(), context.Canceled)
+
+			requests := stub.getHistoryRequests()
+			require.NotEmpty(t, requests)
+			for _, request := range requests {
+				require.Equal(t, onlyLocal, request.OnlyLocal)
+			}
+		})
 	}
 }
 
-func (r *stubTgClient) GetChatHistory(*client.GetChatHistoryRequest) (*client.Messages, error) {
-	if message, ok := <-r.history; ok {
-		return &client.Messages{
-			TotalCount: 1,
-			Messages:   []*client.Message{message},
-		}, nil
+func TestTgScraper_WithHistoryPageSize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	require.Panics(t, func() {
+		scraper.WithHistoryPageSize(0)
+	})
+
+	stub := newStubTgClient()
+	tgScraper := scraper.NewTgScraper(
+		stub,
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithHistoryPageSize(50),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	requests := stub.getHistoryRequests()
+	require.Len(t, requests, 1, "all history fits into a single page")
+	require.Equal(t, int32(50), requests[0].Limit)
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled())
+}
+
+func TestTgScraper_Errors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 25:61 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:40:01"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:40:01"),
+				)},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	// assert parse error is reported
+	err := <-tgScraper.Errors()
+	require.ErrorContains(t, err, "failed to parse time")
+
+	// assert scraper keeps going
+	status := <-updates
+	require.Equal(t, region.KyivCity, status.Region)
+	require.True(t, status.Enabled())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	// assert errors chan is closed
+	_, ok := <-tgScraper.Errors()
+	require.False(t, ok, "errors channel is not closed")
+	require.Zero(t, tgScraper.DroppedErrors())
+}
+
+func TestTgScraper_WithUpdateDedupWindow(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:39:58"),
+				)},
+				// identical repost 5s later
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:40 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:40:03"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🟢 10:06 Відбій тривоги в м. Київ.",
+					strToDate("2024-08-22 10:06:43"),
+				)},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithUpdateDedupWindow(30*time.Second),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	status := <-updates
+	require.True(t, status.Enabled())
+	require.Equal(t, strToDate("2024-08-22 08:39:00"), status.UpdatedAt)
+	// assert the repost is not emitted
+	status = <-updates
+	require.False(t, status.Enabled())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithStreamingHistory(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	historyMessages := []*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19")),
+		createTestMessage("🟢 03:10 Відбій тривоги в Одеська область.", strToDate("2024-08-21 03:10:02")),
+		// enabled and disabled within the same minute
+		createTestMessage("🔴 04:00 Повітряна тривога в Харківська область", strToDate("2024-08-21 04:00:05")),
+		createTestMessage("🟢 04:00 Відбій тривоги в Харківська область.", strToDate("2024-08-21 04:00:50")),
+		// warning doesn't override an active alert
+		createTestMessage("🔴 05:00 Повітряна тривога в м. Київ", strToDate("2024-08-21 05:00:05")),
+		createTestMessage("🟡 05:10 Загроза застосування БпЛА в м. Київ", strToDate("2024-08-21 05:10:05")),
+		// warning after clear
+		createTestMessage("🔴 06:00 Повітряна тривога в Сумська область", strToDate("2024-08-21 06:00:05")),
+		createTestMessage("🟢 06:30 Відбій тривоги в Сумська область.", strToDate("2024-08-21 06:30:05")),
+		createTestMessage("🟡 06:40 Загроза застосування БпЛА в Сумська область", strToDate("2024-08-21 06:40:05")),
+		// warning only
+		createTestMessage("🟡 07:00 Загроза застосування БпЛА в Полтавська область", strToDate("2024-08-21 07:00:05")),
+	}
+
+	alertDataAfterHistory := func(stream bool) []scraper.Status {
+		tgScraper := scraper.NewTgScraper(
+			newStubTgClientWith(historyMessages, nil),
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+			scraper.WithStreamingHistory(stream),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return tgScraper.Run(ctx)
+		})
+		require.NoError(t, tgScraper.WaitForHistory(ctx))
+		cancel()
+		require.ErrorIs(t, g.Wait(), context.Canceled)
+		return tgScraper.AlertData().ToSlice()
 	}
-	return nil, fmt.Errorf("unexpected call, set the oldest message's date to (now - 2 days)")
+
+	expected := alertDataAfterHistory(false)
+	actual := alertDataAfterHistory(true)
+	require.Equal(t, expected, actual)
+
+	// sanity check of the fixture
+	statuses := make(map[region.ID]scraper.Status)
+	for _, status := range actual {
+		statuses[status.Region] = status
+	}
+	require.False(t, statuses[region.Odesa].Enabled())
+	require.False(t, statuses[region.Kharkiv].Enabled())
+	require.True(t, statuses[region.KyivCity].Enabled())
+	require.Equal(t, scraper.LevelWarning, statuses[region.Sumy].Level)
+	require.Equal(t, scraper.LevelWarning, statuses[region.Poltava].Level)
+}
+
+func TestTgScraper_WithStreamingHistory_BoundedMemory(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const total = 1000
+	maxInFlight := func(stream bool) int {
+		stub := &countingHistoryTgClient{
+			total:   total,
+			newest:  strToDate("2024-08-21 20:00:00"),
+			updates: make(chan client.Type),
+		}
+		tgScraper := scraper.NewTgScraper(
+			stub,
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+			scraper.WithStreamingHistory(stream),
+			scraper.WithTracer(stub),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return tgScraper.Run(ctx)
+		})
+		require.NoError(t, tgScraper.WaitForHistory(ctx))
+		cancel()
+		require.ErrorIs(t, g.Wait(), context.Canceled)
+		require.Equal(t, total, stub.parsed)
+		return stub.maxInFlight
+	}
+
+	require.Equal(t, total, maxInFlight(false), "all messages are collected before processing")
+	require.Equal(t, 1, maxInFlight(true), "each message must be processed before the next one is fetched")
+}
+
+func TestTgScraper_WithPerRegionCooldown(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const cooldown = 100 * time.Millisecond
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:39:01"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🟢 08:40 Відбій тривоги в м. Київ.",
+					strToDate("2024-08-22 08:40:01"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в Одеська область",
+					strToDate("2024-08-22 08:39:01"),
+				)},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithPerRegionCooldown(cooldown),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	start := time.Now()
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	status := <-updates
+	require.Equal(t, region.KyivCity, status.Region)
+	require.True(t, status.Enabled())
+
+	// other regions are not affected
+	status = <-updates
+	require.Equal(t, region.Odesa, status.Region)
+
+	// assert AlertData is updated during cooldown
+	require.Eventually(t, func() bool {
+		status, _ := tgScraper.AlertData().GetByRegion(region.KyivCity)
+		return !status.Enabled()
+	}, time.Second, time.Millisecond)
+
+	// assert suppressed state is emitted after cooldown
+	status = <-updates
+	require.GreaterOrEqual(t, time.Since(start), cooldown)
+	require.Equal(t, region.KyivCity, status.Region)
+	require.False(t, status.Enabled())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithPerRegionCooldownSameLevel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const cooldown = 100 * time.Millisecond
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в м. Київ",
+					strToDate("2024-08-22 08:39:01"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:40 Повітряна тривога в м. Київ\nБалістична загроза",
+					strToDate("2024-08-22 08:40:01"),
+				)},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithPerRegionCooldown(cooldown),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	start := time.Now()
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	status := <-updates
+	require.Equal(t, region.KyivCity, status.Region)
+	require.Empty(t, status.ThreatType)
+
+	// assert suppressed status of the same level is emitted after cooldown
+	status = <-updates
+	require.GreaterOrEqual(t, time.Since(start), cooldown)
+	require.Equal(t, region.KyivCity, status.Region)
+	require.Equal(t, scraper.LevelFull, status.Level)
+	require.Equal(t, "ballistic", status.ThreatType)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithSkipHistoryParseErrors(t *testing.T) {
+	historyMessages := []*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19")),
+		createTestMessage("🔴 25:61 Повітряна тривога в м. Київ", strToDate("2024-08-21 02:20:00")),
+		createTestMessage("🔴 02:30 Повітряна тривога в Харківська область", strToDate("2024-08-21 02:30:19")),
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		defer goleak.VerifyNone(t)
+
+		tgScraper := scraper.NewTgScraper(
+			newStubTgClientWith(historyMessages, nil),
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+			scraper.WithSkipHistoryParseErrors(true),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return tgScraper.Run(ctx)
+		})
+		require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+		require.ErrorContains(t, <-tgScraper.Errors(), "failed to parse time")
+		status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+		require.True(t, status.Enabled())
+		status, _ = tgScraper.AlertData().GetByRegion(region.Kharkiv)
+		require.True(t, status.Enabled())
+
+		cancel()
+		require.ErrorIs(t, g.Wait(), context.Canceled)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		defer goleak.VerifyNone(t)
+
+		tgScraper := scraper.NewTgScraper(
+			newStubTgClientWith(historyMessages, nil),
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		)
+		err := tgScraper.Run(context.Background())
+		require.ErrorContains(t, err, "unable to scrape history")
+	})
+}
+
+func TestTgScraper_OutOfOrderPinnedHistory(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	pinned := createTestMessage("🔴 10:00 Повітряна тривога в Львівська область", strToDate("2024-08-10 10:00:00"))
+	pinned.IsPinned = true
+	historyMessages := []*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19")),
+		pinned, // older than history date, but returned between newer messages
+		createTestMessage("🔴 02:30 Повітряна тривога в Харківська область", strToDate("2024-08-21 02:30:19")),
+	}
+	stub := newStubTgClientWith(historyMessages, nil)
+	tgScraper := scraper.NewTgScraper(
+		stub,
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	require.Len(t, stub.getHistoryRequests(), len(historyMessages))
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled())
+	status, _ = tgScraper.AlertData().GetByRegion(region.Kharkiv)
+	require.True(t, status.Enabled())
+	status, _ = tgScraper.AlertData().GetByRegion(region.Lviv)
+	require.False(t, status.Enabled(), "out of range pinned message must be skipped")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithAllowForwardsFrom(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const officialChannelId int64 = -1001234567890
+	allowed := createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19"))
+	allowed.ForwardInfo = &client.MessageForwardInfo{Origin: &client.MessageOriginChannel{ChatId: officialChannelId}}
+	disallowed := createTestMessage("🔴 02:30 Повітряна тривога в Харківська область", strToDate("2024-08-21 02:30:19"))
+	disallowed.ForwardInfo = &client.MessageForwardInfo{Origin: &client.MessageOriginChannel{ChatId: -1009999999999}}
+	hidden := createTestMessage("🔴 02:45 Повітряна тривога в Львівська область", strToDate("2024-08-21 02:45:19"))
+	hidden.ForwardInfo = &client.MessageForwardInfo{Origin: &client.MessageOriginHiddenUser{SenderName: "anonymous"}}
+
+	stub := newStubTgClientWith([]*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		allowed,
+		disallowed,
+		hidden,
+	}, nil)
+	tgScraper := scraper.NewTgScraper(
+		stub,
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithAllowForwardsFrom(officialChannelId),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled(), "forward from the allowed sender must be parsed")
+	status, _ = tgScraper.AlertData().GetByRegion(region.Kharkiv)
+	require.False(t, status.Enabled(), "forward from other sender must be skipped")
+	status, _ = tgScraper.AlertData().GetByRegion(region.Lviv)
+	require.False(t, status.Enabled(), "forward from hidden user must be skipped")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithOnTransition(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	type transition struct {
+		oldStatus, newStatus scraper.Status
+	}
+	transitions := make(chan transition, 10)
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClient(),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithOnTransition(func(oldStatus, newStatus scraper.Status) {
+			if newStatus.Region == region.KyivCity {
+				transitions <- transition{oldStatus, newStatus}
+			}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	enabled := scraper.Status{
+		Region:    region.KyivCity,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-22 08:39:00"),
+		Marker:    '🔴',
+	}
+	require.Equal(t, transition{
+		oldStatus: scraper.Status{Region: region.KyivCity, IsHistory: true}, // seeded default
+		newStatus: enabled,
+	}, <-transitions)
+	require.Equal(t, transition{
+		oldStatus: enabled,
+		newStatus: scraper.Status{
+			Region:    region.KyivCity,
+			Level:     scraper.LevelNone,
+			UpdatedAt: strToDate("2024-08-22 10:06:00"),
+			Marker:    '🟢',
+		},
+	}, <-transitions)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithEventSink(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	enabledMessage := createTestMessage("🔴 08:39 Повітряна тривога в м. Київ", strToDate("2024-08-22 08:39:19"))
+	enabledMessage.Id = 10
+	disabledMessage := createTestMessage("🟢 10:06 Відбій тривоги в м. Київ.", strToDate("2024-08-22 10:06:19"))
+	disabledMessage.Id = 11
+	bot := &botStubTgClient{updates: make(chan client.Type, 2)}
+	bot.updates <- &client.UpdateNewMessage{Message: enabledMessage}
+	bot.updates <- &client.UpdateNewMessage{Message: disabledMessage}
+
+	events := make(chan scraper.Event, 10)
+	tgScraper := scraper.NewTgScraper(bot, scraper.WithEventSink(func(event scraper.Event) {
+		events <- event
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	require.Equal(t, scraper.Event{
+		Region:    region.KyivCity,
+		From:      scraper.LevelNone,
+		To:        scraper.LevelFull,
+		At:        strToDate("2024-08-22 08:39:00"),
+		MessageID: 10,
+	}, <-events)
+	require.Equal(t, scraper.Event{
+		Region:    region.KyivCity,
+		From:      scraper.LevelFull,
+		To:        scraper.LevelNone,
+		At:        strToDate("2024-08-22 10:06:00"),
+		MessageID: 11,
+	}, <-events)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	require.Empty(t, events)
+}
+
+func TestTgScraper_WithKeepalive(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 2)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 08:39 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 08:39:19"),
+	)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 09:10 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 09:10:19"),
+	)}
+	keepalives := make(chan scraper.Status, 10)
+	tgScraper := scraper.NewTgScraper(bot, scraper.WithKeepalive(func(status scraper.Status) {
+		keepalives <- status
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	keepalive := <-keepalives
+	require.Equal(t, region.KyivCity, keepalive.Region)
+	require.Equal(t, strToDate("2024-08-22 09:10:00"), keepalive.UpdatedAt)
+	status, err := tgScraper.AlertData().GetByRegion(region.KyivCity)
+	require.NoError(t, err)
+	require.Equal(t, strToDate("2024-08-22 09:10:00"), status.UpdatedAt, "UpdatedAt must advance")
+	activeSince, ok := tgScraper.AlertData().ActiveSince(region.KyivCity)
+	require.True(t, ok)
+	require.Equal(t, strToDate("2024-08-22 08:39:00"), activeSince, "ActiveSince must be kept")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	require.Empty(t, keepalives, "the alert start is not a keepalive")
+}
+
+func TestTgScraper_WithOrderedStream(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{
+				createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+				createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19")),
+				createTestMessage(
+					"🔴 02:20 Повітряна тривога в Львівська область\n🔴 02:17 Повітряна тривога в Харківська область",
+					strToDate("2024-08-21 02:20:19"),
+				),
+				createTestMessage("🟢 03:00 Відбій тривоги в Одеська область.", strToDate("2024-08-21 03:00:19")),
+			},
+			[]client.Type{
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🔴 08:39 Повітряна тривога в м. Київ", strToDate("2024-08-22 08:40:01"),
+				)},
+				&client.UpdateNewMessage{Message: createTestMessage(
+					"🟢 10:06 Відбій тривоги в м. Київ.", strToDate("2024-08-22 10:06:43"),
+				)},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithOrderedStream(true),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	var stream []scraper.Status
+	for range 6 {
+		stream = append(stream, <-updates)
+	}
+	for i := 1; i < len(stream); i++ {
+		require.False(t, stream[i].UpdatedAt.Before(stream[i-1].UpdatedAt), "%v is before %v", stream[i], stream[i-1])
+	}
+	regions := make([]region.ID, 0, len(stream))
+	for _, status := range stream {
+		regions = append(regions, status.Region)
+	}
+	require.Equal(t, []region.ID{region.Odesa, region.Kharkiv, region.Lviv, region.Odesa, region.KyivCity, region.KyivCity}, regions)
+	require.True(t, stream[3].IsHistory)
+	require.False(t, stream[4].IsHistory)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_CancelWithFullUpdatesChan(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClient(),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	_ = tgScraper.UpdatesChan() // never read
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tgScraper.Run(ctx)
+	}()
+
+	// the first update fills the channel's buffer, the second one blocks
+	require.Eventually(t, func() bool {
+		status, _ := tgScraper.AlertData().GetByRegion(region.KyivCity)
+		return status.UpdatedAt.Equal(strToDate("2024-08-22 10:06:00"))
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Run is blocked on the full updates channel")
+	}
+}
+
+func TestTgScraper_WithDeletedMessages(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	withId := func(id int64, message *client.Message) *client.Message {
+		message.Id = id
+		return message
+	}
+	historyMessages := []*client.Message{
+		withId(1, createTestMessage("old message", strToDate("2024-08-19 19:46:52"))),
+		withId(2, createTestMessage("🟢 01:00 Відбій тривоги в Одеська область.", strToDate("2024-08-21 01:00:10"))),
+		withId(3, createTestMessage("🔴 02:15 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19"))),
+	}
+	updates := []client.Type{
+		&client.UpdateNewMessage{Message: withId(4, createTestMessage(
+			"🔴 08:39 Повітряна тривога в м. Київ", strToDate("2024-08-22 08:40:01"),
+		))},
+		&client.UpdateNewMessage{Message: withId(5, createTestMessage(
+			"🟢 08:45 Відбій тривоги в Автономна Республіка Крим.", strToDate("2024-08-22 08:45:01"),
+		))},
+		&client.UpdateDeleteMessages{ChatId: airAlertUaChannelID, MessageIds: []int64{3}, FromCache: true},
+		&client.UpdateDeleteMessages{ChatId: airAlertUaChannelID, MessageIds: []int64{3, 4, 5}, IsPermanent: true},
+	}
+	// sent after the state reverted by the updates above is checked
+	laterUpdates := []client.Type{
+		&client.UpdateNewMessage{Message: withId(6, createTestMessage(
+			"🔴 09:00 Повітряна тривога в Одеська область", strToDate("2024-08-22 09:00:01"),
+		))},
+		&client.UpdateNewMessage{Message: withId(7, createTestMessage(
+			"🟢 09:30 Відбій тривоги в Одеська область.", strToDate("2024-08-22 09:30:01"),
+		))},
+		&client.UpdateDeleteMessages{ChatId: airAlertUaChannelID, MessageIds: []int64{6}, IsPermanent: true},
+		&client.UpdateNewMessage{Message: withId(8, createTestMessage(
+			"🔴 09:40 Повітряна тривога в м. Київ", strToDate("2024-08-22 09:40:01"),
+		))},
+	}
+	stub := newStubTgClientWith(historyMessages, nil)
+	tgScraper := scraper.NewTgScraper(
+		stub,
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithDeletedMessages(true),
+	)
+	updatesChan := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	go func() {
+		for _, update := range updates {
+			stub.updates <- update
+		}
+	}()
+
+	require.Equal(t, region.KyivCity, (<-updatesChan).Region)
+	require.Equal(t, region.Crimea, (<-updatesChan).Region)
+
+	// no messages left, reset to the seeded long-running alert
+	crimea := <-updatesChan
+	require.Equal(t, region.Crimea, crimea.Region)
+	require.Equal(t, scraper.LevelFull, crimea.Level)
+	require.Equal(t, 2022, crimea.UpdatedAt.Year())
+
+	// re-derived from the remaining message
+	odesa := scraper.Status{
+		Region:          region.Odesa,
+		Level:           scraper.LevelNone,
+		UpdatedAt:       strToDate("2024-08-21 01:00:00"),
+		Marker:          '🟢',
+		IsHistory:       true,
+		SourceMessageID: 2,
+	}
+	require.Equal(t, odesa, <-updatesChan)
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.Equal(t, odesa, status)
+
+	// no messages left, reset to default
+	kyiv := scraper.Status{Region: region.KyivCity, IsHistory: true}
+	require.Equal(t, kyiv, <-updatesChan)
+	status, _ = tgScraper.AlertData().GetByRegion(region.KyivCity)
+	require.Equal(t, kyiv, status)
+
+	go func() {
+		for _, update := range laterUpdates {
+			stub.updates <- update
+		}
+	}()
+	require.Equal(t, region.Odesa, (<-updatesChan).Region)
+	require.Equal(t, region.Odesa, (<-updatesChan).Region)
+	// the deleted message isn't the latest one of the region, so no update is sent
+	require.Equal(t, region.KyivCity, (<-updatesChan).Region)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithParseWorkers(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	require.Panics(t, func() {
+		scraper.WithParseWorkers(0)
+	})
+
+	regions := []region.ID{region.Odesa, region.Kharkiv, region.Lviv, region.KyivCity, region.Sumy}
+	const messagesPerRegion = 40
+	var updates []client.Type
+	sentAt := strToDate("2024-08-22 00:00:00")
+	for i := range messagesPerRegion {
+		for _, id := range regions {
+			sentAt = sentAt.Add(time.Minute)
+			text := fmt.Sprintf("🟢 %s Відбій тривоги в %s.", sentAt.Format("15:04"), id)
+			if i%2 == 0 {
+				text = fmt.Sprintf("🔴 %s Повітряна тривога в %s", sentAt.Format("15:04"), id)
+			}
+			updates = append(updates, &client.UpdateNewMessage{Message: createTestMessage(text, sentAt)})
+		}
+	}
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			updates,
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithParseWorkers(4),
+	)
+	updatesChan := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	var lastUpdatedAt time.Time
+	received := make(map[region.ID]int)
+	for range len(updates) {
+		status := <-updatesChan
+		require.True(t, status.UpdatedAt.After(lastUpdatedAt), "updates must be emitted in order")
+		lastUpdatedAt = status.UpdatedAt
+		require.Equal(t, received[status.Region]%2 == 0, status.Enabled())
+		received[status.Region]++
+	}
+	for _, id := range regions {
+		require.Equal(t, messagesPerRegion, received[id])
+		status, _ := tgScraper.AlertData().GetByRegion(id)
+		require.False(t, status.Enabled())
+	}
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithSecondPrecision(t *testing.T) {
+	for _, secondPrecision := range []bool{false, true} {
+		t.Run(fmt.Sprint(secondPrecision), func(t *testing.T) {
+			defer goleak.VerifyNone(t)
+
+			tgScraper := scraper.NewTgScraper(
+				newStubTgClient(),
+				scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+				scraper.WithSecondPrecision(secondPrecision),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() error {
+				return tgScraper.Run(ctx)
+			})
+			require.NoError(t, tgScraper.WaitForHistory(ctx))
+			cancel()
+			require.ErrorIs(t, g.Wait(), context.Canceled)
+
+			// "🔴 02:15 Повітряна тривога в Одеська область" sent at 02:15:19
+			status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+			expected := strToDate("2024-08-21 02:15:00")
+			if secondPrecision {
+				expected = strToDate("2024-08-21 02:15:19")
+			}
+			require.Equal(t, expected, status.UpdatedAt)
+		})
+	}
+}
+
+func TestTgScraper_ListenerRestart(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// the first listener is closed after the first update, the second one sends nil
+	listeners := make(chan chan client.Type, 3)
+	for i, updates := range [][]client.Type{
+		{&client.UpdateNewMessage{Message: createTestMessage("🔴 08:39 Повітряна тривога в м. Київ", strToDate("2024-08-22 08:40:01"))}},
+		{nil},
+		{&client.UpdateNewMessage{Message: createTestMessage("🟢 10:06 Відбій тривоги в м. Київ.", strToDate("2024-08-22 10:06:43"))}},
+	} {
+		listener := make(chan client.Type, len(updates))
+		for _, update := range updates {
+			listener <- update
+		}
+		if i == 0 {
+			close(listener) // buffered update is still received
+		}
+		listeners <- listener
+	}
+	stub := &restartingStubTgClient{listeners: listeners}
+
+	tgScraper := scraper.NewTgScraper(stub)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	require.True(t, (<-updates).Enabled())
+	require.False(t, (<-updates).Enabled(), "must resume after the listener restart")
+	require.Equal(t, 3, stub.getListenerCalls())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_MediaCaption(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	withContent := func(message *client.Message, content client.MessageContent) *client.Message {
+		message.Content = content
+		return message
+	}
+	caption := func(text string) *client.FormattedText {
+		return &client.FormattedText{Text: text}
+	}
+	historyMessages := []*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		withContent(createTestMessage("", strToDate("2024-08-21 02:15:19")),
+			&client.MessagePhoto{Caption: caption("🔴 02:15 Повітряна тривога в Одеська область")}),
+		withContent(createTestMessage("", strToDate("2024-08-21 02:16:19")),
+			&client.MessageVideo{Caption: caption("🔴 02:16 Повітряна тривога в Харківська область")}),
+	}
+	updates := []client.Type{
+		&client.UpdateNewMessage{Message: withContent(createTestMessage("", strToDate("2024-08-22 08:40:01")),
+			&client.MessageAnimation{Caption: caption("🔴 08:39 Повітряна тривога в м. Київ")})},
+	}
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith(historyMessages, updates),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	updatesChan := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	status, _ := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled())
+	status, _ = tgScraper.AlertData().GetByRegion(region.Kharkiv)
+	require.True(t, status.Enabled())
+	status = <-updatesChan
+	require.Equal(t, region.KyivCity, status.Region)
+	require.True(t, status.Enabled())
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_Drill(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	updates := []client.Type{
+		&client.UpdateNewMessage{Message: createTestMessage(
+			"🔴 08:39 Повітряна тривога в м. Київ (навчальна).\nСлідкуйте за подальшими повідомленнями.",
+			strToDate("2024-08-22 08:40:01"),
+		)},
+		&client.UpdateNewMessage{Message: createTestMessage(
+			"🔴 08:41 Повітряна тривога в Одеська область",
+			strToDate("2024-08-22 08:41:01"),
+		)},
+	}
+	tgScraper := scraper.NewTgScraper(
+		newStubTgClientWith([]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))}, updates),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	updatesChan := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	drill := <-updatesChan
+	require.Equal(t, region.KyivCity, drill.Region)
+	require.True(t, drill.Enabled())
+	require.True(t, drill.IsDrill)
+	require.False(t, (<-updatesChan).IsDrill)
+
+	status, _ := tgScraper.AlertData().GetByRegion(region.KyivCity)
+	require.Equal(t, drill, status, "drill must be stored")
+	active := tgScraper.AlertData().Filter(func(status scraper.Status) bool {
+		return status.Enabled() && !status.IsDrill
+	})
+	require.NotContains(t, active, drill)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_BotClient(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 1)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 08:39 Повітряна тривога в м. Київ\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
+		strToDate("2024-08-22 08:40:01"),
+	)}
+	tgScraper := scraper.NewTgScraper(bot)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	require.Equal(t, scraper.Status{
+		Region:    region.KyivCity,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-22 08:39:00"),
+		Marker:    '🔴',
+	}, <-updates)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithExpectedChatTitle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ctx := context.Background()
+	tgScraper := scraper.NewTgScraper(
+		&botStubTgClient{updates: make(chan client.Type)},
+		scraper.WithExpectedChatTitle(stubChatTitle),
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	tgScraper = scraper.NewTgScraper(
+		&botStubTgClient{updates: make(chan client.Type)},
+		scraper.WithExpectedChatTitle("Some other channel"),
+	)
+	updates := tgScraper.UpdatesChan()
+	err := tgScraper.Run(context.Background())
+	require.ErrorIs(t, err, scraper.ErrChatMismatch)
+	require.ErrorContains(t, err, `expected "Some other channel", got "Повітряна тривога"`)
+	_, ok := <-updates
+	require.False(t, ok)
+}
+
+func TestTgScraper_UpdatesBatched(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 3)}
+	for _, text := range []string{
+		"🔴 08:39 Повітряна тривога в м. Київ",
+		"🔴 08:40 Повітряна тривога в Одеська область",
+		"🟢 08:41 Відбій тривоги в м. Київ.",
+	} {
+		bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(text, strToDate("2024-08-22 08:41:01"))}
+	}
+	tgScraper := scraper.NewTgScraper(bot)
+	batches := tgScraper.UpdatesBatched(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	batch := <-batches
+	require.Len(t, batch, 3)
+	require.Equal(t, []region.ID{region.KyivCity, region.Odesa, region.KyivCity}, []region.ID{
+		batch[0].Region, batch[1].Region, batch[2].Region,
+	})
+	require.Equal(t, scraper.LevelNone, batch[2].Level)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	_, ok := <-batches
+	require.False(t, ok)
+}
+
+func TestTgScraper_WithFutureTolerance(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 1)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 12:03 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 12:00:30"),
+	)}
+	tgScraper := scraper.NewTgScraper(bot, scraper.WithFutureTolerance(time.Minute))
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	// 12:03 is more than a minute after 12:00:30, so it's the previous day
+	require.Equal(t, strToDate("2024-08-21 12:03:00"), (<-updates).UpdatedAt)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	require.Panics(t, func() {
+		scraper.WithFutureTolerance(-time.Second)
+	})
+}
+
+func TestTgScraper_WithTimeFallback(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	historyMessages := []*client.Message{
+		createTestMessage("old message", strToDate("2024-08-19 19:46:52")),
+		createTestMessage("🔴 99:99 Повітряна тривога в Одеська область", strToDate("2024-08-21 02:15:19")),
+	}
+	run := func(fallback bool) (*scraper.TgScraper, error) {
+		tgScraper := scraper.NewTgScraper(
+			newStubTgClientWith(historyMessages, nil),
+			scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+			scraper.WithTimeFallback(fallback),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return tgScraper.Run(ctx)
+		})
+		_ = tgScraper.WaitForHistory(ctx) // history is done, or the scraper has stopped
+		cancel()
+		return tgScraper, g.Wait()
+	}
+
+	_, err := run(false)
+	require.ErrorContains(t, err, "failed to parse time")
+
+	tgScraper, err := run(true)
+	require.ErrorIs(t, err, context.Canceled)
+	status, err := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.NoError(t, err)
+	require.True(t, status.Enabled())
+	require.Equal(t, strToDate("2024-08-21 02:15:00"), status.UpdatedAt, "message date is used")
+}
+
+func TestTgScraper_WithOnChangeErr(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 1)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 08:39 Повітряна тривога в м. Київ",
+		strToDate("2024-08-22 08:40:01"),
+	)}
+	var attempts int
+	delivered := make(chan scraper.Status, 1)
+	tgScraper := scraper.NewTgScraper(bot, scraper.WithOnChangeErr(func(status scraper.Status) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("database is unavailable")
+		}
+		delivered <- status
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	status := <-delivered
+	require.Equal(t, region.KyivCity, status.Region)
+	require.True(t, status.Enabled())
+	require.Equal(t, 2, attempts)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+	require.Empty(t, tgScraper.Errors(), "delivered update must not be reported")
+}
+
+func TestTgScraper_Resync(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	now := time.Now().Truncate(time.Minute)
+	message := func(id int64, format string, date time.Time) *client.Message {
+		message := createTestMessage(fmt.Sprintf(format, date.In(kyivLocation).Format("15:04")), date.Add(10*time.Second))
+		message.Id = id
+		return message
+	}
+	stub := &resyncStubTgClient{updates: make(chan client.Type)}
+	stub.add(message(1, "🔴 %s Повітряна тривога в Одеська область", now.Add(-3*time.Hour)))
+	stub.add(message(2, "🔴 %s Повітряна тривога в Харківська область", now.Add(-20*time.Minute)))
+	tgScraper := scraper.NewTgScraper(stub, scraper.WithResyncPeriod(time.Hour))
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	// clears are missed, e.g. during a listener outage
+	stub.add(message(3, "🟢 %s Відбій тривоги в Одеська область.", now.Add(-2*time.Hour))) // older than resync period
+	stub.add(message(4, "🟢 %s Відбій тривоги в Харківська область.", now.Add(-10*time.Minute)))
+
+	g.Go(func() error {
+		return tgScraper.Resync(ctx)
+	})
+	status := <-updates
+	require.Equal(t, region.Kharkiv, status.Region)
+	require.False(t, status.Enabled())
+	status, _ = tgScraper.AlertData().GetByRegion(region.Kharkiv)
+	require.False(t, status.Enabled(), "missed clear must be recovered")
+	status, _ = tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled(), "only the resync period is fetched")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	// the updates channel is closed, so a late resync must not send to it
+	stub.add(message(5, "🔴 %s Повітряна тривога в Харківська область", now.Add(-5*time.Minute)))
+	require.ErrorIs(t, tgScraper.Resync(context.Background()), scraper.ErrStopped)
+}
+
+func TestTgScraper_ResyncConcurrentUpdate(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	now := time.Now().Truncate(time.Minute)
+	message := func(id int64, format string, date time.Time) *client.Message {
+		message := createTestMessage(fmt.Sprintf(format, date.In(kyivLocation).Format("15:04")), date.Add(10*time.Second))
+		message.Id = id
+		return message
+	}
+	stub := &resyncStubTgClient{updates: make(chan client.Type)}
+	stub.add(message(1, "🔴 %s Повітряна тривога в Харківська область", now.Add(-20*time.Minute)))
+	var tgScraper *scraper.TgScraper
+	var once sync.Once
+	tgScraper = scraper.NewTgScraper(
+		stub,
+		scraper.WithResyncPeriod(time.Hour),
+		scraper.WithOnTransition(func(_, newStatus scraper.Status) {
+			if newStatus.Region == region.Kharkiv && !newStatus.Enabled() {
+				once.Do(func() {
+					// a live update lands right after the recovered clear is stored
+					tgScraper.AlertData().Set(scraper.Status{Region: region.Kharkiv, Level: scraper.LevelFull, UpdatedAt: now})
+				})
+			}
+		}),
+	)
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	stub.add(message(2, "🟢 %s Відбій тривоги в Харківська область.", now.Add(-10*time.Minute)))
+	g.Go(func() error {
+		return tgScraper.Resync(ctx)
+	})
+	status := <-updates
+	require.Equal(t, region.Kharkiv, status.Region)
+	require.False(t, status.Enabled(), "the recovered clear must be reported, not the concurrent update")
+	require.WithinDuration(t, now.Add(-10*time.Minute), status.UpdatedAt, 0)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+// resyncStubTgClient returns history from added messages, newest first, and no updates.
+type resyncStubTgClient struct {
+	updates chan client.Type
+
+	lock     sync.Mutex
+	messages []*client.Message // sorted by id
+}
+
+func (r *resyncStubTgClient) add(message *client.Message) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+func (r *resyncStubTgClient) GetListener() *client.Listener {
+	return &client.Listener{
+		Updates: r.updates,
+	}
+}
+
+func (r *resyncStubTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+func (r *resyncStubTgClient) GetChatHistory(req *client.GetChatHistoryRequest) (*client.Messages, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	messages := &client.Messages{}
+	for _, message := range slices.Backward(r.messages) {
+		if req.FromMessageId != 0 && message.Id >= req.FromMessageId {
+			continue
+		}
+		messages.Messages = append(messages.Messages, message)
+		if len(messages.Messages) >= int(req.Limit) {
+			break
+		}
+	}
+	return messages, nil
+}
+
+func TestTgScraper_WithFullChannelPolicy(t *testing.T) {
+	statuses := []scraper.Status{
+		{Region: region.KyivCity, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 08:39:00")},
+		{Region: region.Odesa, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 08:40:00")},
+		{Region: region.Lviv, Level: scraper.LevelFull, UpdatedAt: strToDate("2024-08-22 08:41:00")},
+	}
+
+	tests := []struct {
+		policy   scraper.FullChannelPolicy
+		expected scraper.Status
+	}{
+		{scraper.DropNewest, statuses[0]},
+		{scraper.DropOldest, statuses[2]},
+	}
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			tgScraper := scraper.NewTgScraper(&botStubTgClient{}, scraper.WithFullChannelPolicy(test.policy))
+			updates := tgScraper.UpdatesChan()
+			for _, status := range statuses {
+				tgScraper.SendUpdate(context.Background(), status) // must not block on the full channel
+			}
+			require.Len(t, updates, 1)
+			require.Equal(t, test.expected, <-updates)
+		})
+	}
+}
+
+func TestTgScraper_WithPinnedSummary(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	summary := createTestMessage("Тривога триває в:\n🔴 Харківська область\n🔴 Одеська область", strToDate("2024-08-21 02:20:00"))
+	summary.IsPinned = true
+	stub := &pinnedStubTgClient{
+		stubTgClient: newStubTgClientWith([]*client.Message{
+			createTestMessage("🔴 02:15 Повітряна тривога в Львівська область", strToDate("2024-08-21 02:15:19")), // before the summary
+			createTestMessage("🟢 02:30 Відбій тривоги в Харківська область.", strToDate("2024-08-21 02:30:19")),
+		}, nil),
+		pinned: summary,
+	}
+	tgScraper := scraper.NewTgScraper(
+		stub,
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+		scraper.WithPinnedSummary(true),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+
+	require.Len(t, stub.getHistoryRequests(), 2, "history older than the summary must not be fetched")
+	enabled := tgScraper.AlertData().Filter(func(status scraper.Status) bool {
+		return status.Enabled()
+	})
+	require.Len(t, enabled, 1)
+	require.Equal(t, region.Odesa, enabled[0].Region)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithRegionFilter(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	bot := &botStubTgClient{updates: make(chan client.Type, 2)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 10:00 Повітряна тривога в Львівська область",
+		strToDate("2024-08-22 10:00:30"),
+	)}
+	bot.updates <- &client.UpdateNewMessage{Message: createTestMessage(
+		"🔴 10:01 Повітряна тривога в Одеська область",
+		strToDate("2024-08-22 10:01:30"),
+	)}
+	tgScraper := scraper.NewTgScraper(bot, scraper.WithRegionFilter(region.Odesa, region.Mykolaiv, region.Crimea))
+	updates := tgScraper.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.Run(ctx)
+	})
+
+	require.Equal(t, region.Odesa, (<-updates).Region, "update of the filtered out region must not be sent")
+	_, err := tgScraper.AlertData().GetByRegion(region.Lviv)
+	require.ErrorIs(t, err, scraper.ErrRegionNotFound)
+	_, err = tgScraper.AlertData().GetByRegion(region.Luhansk)
+	require.ErrorIs(t, err, scraper.ErrRegionNotFound, "long-running alerts respect the filter")
+	crimea, err := tgScraper.AlertData().GetByRegion(region.Crimea)
+	require.NoError(t, err)
+	require.True(t, crimea.Enabled())
+	require.Len(t, tgScraper.AlertData().GetAll(), 3)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_WithHistoryConcurrency(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	newest := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	historyFromDate := newest.Add(-2 * 24 * time.Hour)
+	stub := newPartitionedHistoryTgClient(historyFromDate.Add(-time.Hour), newest, 10*time.Minute)
+	backfill := func(concurrency int) *scraper.AlertData {
+		tgScraper := scraper.NewTgScraper(
+			stub,
+			scraper.WithHistoryFromDate(historyFromDate),
+			scraper.WithHistoryConcurrency(concurrency),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return tgScraper.Run(ctx)
+		})
+		require.NoError(t, tgScraper.WaitForHistory(ctx))
+		cancel()
+		require.ErrorIs(t, g.Wait(), context.Canceled)
+		return tgScraper.AlertData()
+	}
+
+	sequential := backfill(1)
+	require.Zero(t, stub.byDateCalls.Load())
+	concurrent := backfill(4)
+	require.EqualValues(t, 3, stub.byDateCalls.Load())
+	require.True(t, sequential.Equal(concurrent))
+	for _, id := range []region.ID{region.Odesa, region.Kharkiv, region.Lviv} {
+		status, err := concurrent.GetByRegion(id)
+		require.NoError(t, err)
+		require.False(t, status.UpdatedAt.IsZero(), "%s must be backfilled", id)
+	}
+
+	require.Panics(t, func() {
+		scraper.WithHistoryConcurrency(0)
+	})
+}
+
+// partitionedHistoryTgClient serves the history of alternating alert and clear messages from oldest to newest,
+// every interval, ids ascending. Both history and message by date requests are served from any point.
+type partitionedHistoryTgClient struct {
+	messages    []*client.Message // oldest first
+	byDateCalls atomic.Int32
+}
+
+func newPartitionedHistoryTgClient(oldest, newest time.Time, interval time.Duration) *partitionedHistoryTgClient {
+	stub := &partitionedHistoryTgClient{}
+	regions := []string{"Одеська область", "Харківська область", "Львівська область"}
+	for i := 0; !oldest.Add(time.Duration(i) * interval).After(newest); i++ {
+		date := oldest.Add(time.Duration(i) * interval)
+		format := "🔴 %s Повітряна тривога в %s"
+		if i%2 == 1 {
+			format = "🟢 %s Відбій тривоги в %s."
+		}
+		message := createTestMessage(
+			fmt.Sprintf(format, date.In(kyivLocation).Format("15:04"), regions[i%len(regions)]),
+			date.Add(10*time.Second),
+		)
+		message.Id = int64(i + 1)
+		stub.messages = append(stub.messages, message)
+	}
+	return stub
+}
+
+func (r *partitionedHistoryTgClient) GetListener() *client.Listener {
+	return &client.Listener{Updates: make(chan client.Type)}
+}
+
+func (r *partitionedHistoryTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+// GetChatHistory returns the message preceding FromMessageId, or the newest one if FromMessageId is zero.
+func (r *partitionedHistoryTgClient) GetChatHistory(req *client.GetChatHistoryRequest) (*client.Messages, error) {
+	i := len(r.messages)
+	if req.FromMessageId != 0 {
+		i = int(req.FromMessageId) - 1
+	}
+	if i == 0 {
+		return &client.Messages{}, nil
+	}
+	return &client.Messages{TotalCount: 1, Messages: []*client.Message{r.messages[i-1]}}, nil
+}
+
+// GetChatMessageByDate returns the last message sent no later than Date.
+func (r *partitionedHistoryTgClient) GetChatMessageByDate(req *client.GetChatMessageByDateRequest) (*client.Message, error) {
+	r.byDateCalls.Add(1)
+	i, _ := slices.BinarySearchFunc(r.messages, req.Date+1, func(message *client.Message, date int32) int {
+		return cmp.Compare(message.Date, date)
+	})
+	if i == 0 {
+		return nil, nil
+	}
+	return r.messages[i-1], nil
+}
+
+func TestTgScraper_RunWithRecovery(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	newest := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	historyFromDate := newest.Add(-time.Hour)
+	stub := &flakyTgClient{
+		partitionedHistoryTgClient: newPartitionedHistoryTgClient(historyFromDate.Add(-time.Hour), newest, 10*time.Minute),
+	}
+	tgScraper := scraper.NewTgScraper(stub, scraper.WithHistoryFromDate(historyFromDate))
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return tgScraper.RunWithRecovery(ctx, scraper.RecoveryPolicy{MinBackoff: time.Millisecond})
+	})
+
+	require.ErrorContains(t, <-tgScraper.Errors(), "connection lost")
+	require.NoError(t, tgScraper.WaitForHistory(ctx))
+	status, err := tgScraper.AlertData().GetByRegion(region.Odesa)
+	require.NoError(t, err)
+	require.False(t, status.UpdatedAt.IsZero(), "history must be fetched after recovery")
+	require.Eventually(t, func() bool {
+		return stub.listeners.Load() == 2
+	}, time.Second, time.Millisecond, "listener must be acquired again")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestTgScraper_RunWithRecovery_Unrecoverable(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tgScraper := scraper.NewTgScraper(newStubTgClient(), scraper.WithExpectedChatTitle("Тривога"))
+	err := tgScraper.RunWithRecovery(context.Background(), scraper.RecoveryPolicy{MinBackoff: time.Millisecond})
+	require.ErrorIs(t, err, scraper.ErrChatMismatch)
+
+	tgScraper = scraper.NewTgScraper(
+		newStubTgClientWith(
+			[]*client.Message{createTestMessage("old message", strToDate("2024-08-19 19:46:52"))},
+			[]client.Type{
+				&client.UpdateAuthorizationState{AuthorizationState: &client.AuthorizationStateClosed{}},
+			},
+		),
+		scraper.WithHistoryFromDate(strToDate("2024-08-20 00:00:00")),
+	)
+	err = tgScraper.RunWithRecovery(context.Background(), scraper.RecoveryPolicy{MinBackoff: time.Millisecond})
+	require.ErrorIs(t, err, scraper.ErrUnauthorized, "a revoked session must not be retried")
+
+	stub := &flakyTgClient{
+		partitionedHistoryTgClient: newPartitionedHistoryTgClient(time.Now().Add(-time.Hour), time.Now(), time.Minute),
+		failures:                   3,
+	}
+	tgScraper = scraper.NewTgScraper(stub)
+	err = tgScraper.RunWithRecovery(context.Background(), scraper.RecoveryPolicy{MinBackoff: time.Millisecond, MaxAttempts: 2})
+	require.ErrorContains(t, err, "giving up after 2 attempts")
+}
+
+// flakyTgClient is partitionedHistoryTgClient failing the first failures (default 1) history requests.
+type flakyTgClient struct {
+	*partitionedHistoryTgClient
+	failures     int32
+	historyCalls atomic.Int32
+	listeners    atomic.Int32
+}
+
+func (r *flakyTgClient) GetListener() *client.Listener {
+	r.listeners.Add(1)
+	return r.partitionedHistoryTgClient.GetListener()
+}
+
+func (r *flakyTgClient) GetChatHistory(req *client.GetChatHistoryRequest) (*client.Messages, error) {
+	if r.historyCalls.Add(1) <= max(r.failures, 1) {
+		return nil, errors.New("connection lost")
+	}
+	return r.partitionedHistoryTgClient.GetChatHistory(req)
+}
+
+// pinnedStubTgClient is stubTgClient able to get the pinned message.
+type pinnedStubTgClient struct {
+	*stubTgClient
+	pinned *client.Message
+}
+
+func (r *pinnedStubTgClient) GetChatPinnedMessage(*client.GetChatPinnedMessageRequest) (*client.Message, error) {
+	return r.pinned, nil
+}
+
+const stubChatTitle = "Повітряна тривога"
+
+// countingHistoryTgClient lazily generates total history messages, the newest one at newest, a minute apart.
+// As a Tracer it counts parsed messages, to track how many fetched messages are held unprocessed.
+type countingHistoryTgClient struct {
+	total   int
+	newest  time.Time
+	updates chan client.Type
+
+	lock        sync.Mutex
+	fetched     int
+	parsed      int
+	maxInFlight int
+}
+
+func (r *countingHistoryTgClient) GetListener() *client.Listener {
+	return &client.Listener{
+		Updates: r.updates,
+	}
+}
+
+func (r *countingHistoryTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+func (r *countingHistoryTgClient) GetChatHistory(*client.GetChatHistoryRequest) (*client.Messages, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.fetched == r.total {
+		return &client.Messages{}, nil
+	}
+	r.fetched++
+	r.maxInFlight = max(r.maxInFlight, r.fetched-r.parsed)
+	date := r.newest.Add(-time.Duration(r.fetched) * time.Minute)
+	message := createTestMessage(fmt.Sprintf("🔴 %s Повітряна тривога в Одеська область", date.Format("15:04")), date)
+	message.Id = int64(r.total - r.fetched + 1)
+	return &client.Messages{Messages: []*client.Message{message}}, nil
+}
+
+func (r *countingHistoryTgClient) Start(ctx context.Context, name string) (context.Context, scraper.Span) {
+	if name == "parseMessage" {
+		r.lock.Lock()
+		r.parsed++
+		r.lock.Unlock()
+	}
+	return ctx, recordingSpan{}
+}
+
+// botStubTgClient mimics a bot client: it has no access to chat history and receives channel posts as updates.
+type botStubTgClient struct {
+	updates chan client.Type
+}
+
+func (r *botStubTgClient) GetListener() *client.Listener {
+	return &client.Listener{
+		Updates: r.updates,
+	}
+}
+
+func (r *botStubTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+func (r *botStubTgClient) GetChatHistory(*client.GetChatHistoryRequest) (*client.Messages, error) {
+	return &client.Messages{}, nil
+}
+
+// restartingStubTgClient returns a new listener from listeners on each GetListener call and has no history.
+type restartingStubTgClient struct {
+	listeners chan chan client.Type
+
+	lock          sync.Mutex
+	listenerCalls int
+}
+
+func (r *restartingStubTgClient) GetListener() *client.Listener {
+	r.lock.Lock()
+	r.listenerCalls++
+	r.lock.Unlock()
+	return &client.Listener{
+		Updates: <-r.listeners,
+	}
+}
+
+func (r *restartingStubTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+func (r *restartingStubTgClient) GetChatHistory(*client.GetChatHistoryRequest) (*client.Messages, error) {
+	return &client.Messages{}, nil
+}
+
+func (r *restartingStubTgClient) getListenerCalls() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.listenerCalls
+}
+
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []string
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, scraper.Span) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, name)
+	return ctx, recordingSpan{}
+}
+
+func (r *recordingTracer) names() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return slices.Clone(r.spans)
+}
+
+type recordingSpan struct{}
+
+func (recordingSpan) SetAttribute(string, any) {}
+func (recordingSpan) RecordError(error)        {}
+func (recordingSpan) End()                     {}
+
+type stubTgClient struct {
+	history chan *client.Message
+	updates chan client.Type
+
+	lock            sync.Mutex
+	historyRequests []client.GetChatHistoryRequest
+}
+
+func newStubTgClient() *stubTgClient {
+	historyMessages := []*client.Message{
+		createTestMessage(
+			"🟢 19:46 Відбій тривоги в Одеська область.\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
+			strToDate("2024-08-19 19:46:52"),
+		),
+		createTestMessage(
+			"🔴 02:15 Повітряна тривога в Одеська область\nСлідкуйте за подальшими повідомленнями.\n#Одеська_область",
+			strToDate("2024-08-21 02:15:19"),
+		),
+	}
+
+	updatesMessages := []*client.Message{
+		createTestMessage(
+			"🔴 08:39 Повітряна тривога в м. Київ\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
+			strToDate("2024-08-22 08:40:01"),
+		),
+		createTestMessage(
+			"🟢 10:06 Відбій тривоги в м. Київ.\nСлідкуйте за подальшими повідомленнями.\n#м_Київ",
+			strToDate("2024-08-22 10:06:43"),
+		),
+	}
+	updates := make([]client.Type, 0, len(updatesMessages))
+	for _, message := range updatesMessages {
+		updates = append(updates, &client.UpdateNewMessage{Message: message})
+	}
+
+	return newStubTgClientWith(historyMessages, updates)
+}
+
+// newStubTgClientWith creates stubTgClient serving historyMessages (oldest first) and then updates.
+// The oldest history message must be older than the scraper's history date.
+func newStubTgClientWith(historyMessages []*client.Message, updatesTypes []client.Type) *stubTgClient {
+	historyMessages = slices.Clone(historyMessages)
+	history := make(chan *client.Message, len(historyMessages))
+	defer close(history)
+	slices.Reverse(historyMessages) // newer messages first
+	for _, message := range historyMessages {
+		history <- message
+	}
+
+	updates := make(chan client.Type, len(updatesTypes))
+	//defer close(updates)
+	for _, update := range updatesTypes {
+		updates <- update
+	}
+
+	return &stubTgClient{
+		history: history,
+		updates: updates,
+	}
+}
+
+func (r *stubTgClient) GetListener() *client.Listener {
+	return &client.Listener{
+		Updates: r.updates,
+	}
+}
+
+func (r *stubTgClient) GetChat(req *client.GetChatRequest) (*client.Chat, error) {
+	return &client.Chat{Id: req.ChatId, Title: stubChatTitle}, nil
+}
+
+func (r *stubTgClient) GetChatHistory(req *client.GetChatHistoryRequest) (*client.Messages, error) {
+	r.lock.Lock()
+	r.historyRequests = append(r.historyRequests, *req)
+	r.lock.Unlock()
+
+	messages := &client.Messages{}
+	for message := range r.history {
+		messages.Messages = append(messages.Messages, message)
+		if len(messages.Messages) >= int(req.Limit) {
+			break
+		}
+	}
+	if len(messages.Messages) == 0 {
+		return nil, fmt.Errorf("unexpected call, set the oldest message's date to (now - 2 days)")
+	}
+	messages.TotalCount = int32(len(messages.Messages))
+	return messages, nil
+}
+
+func (r *stubTgClient) getHistoryRequests() []client.GetChatHistoryRequest {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return slices.Clone(r.historyRequests)
 }
 
 func createTestMessage(text string, date time.Time) *client.Message {
 	return &client.Message{
-		Date: int32(date.Unix()),
+		ChatId: airAlertUaChannelID,
+		Date:   int32(date.Unix()),
 		Content: &client.MessageText{
 			Text: &client.FormattedText{
 				Text: text,
@@ -4,7 +4,13 @@ import (
 	"github.com/zelenin/go-tdlib/client"
 )
 
+// TgClient is the subset of the tdlib client used by TgScraper.
+// Both user and bot clients can satisfy it: channel posts arrive as client.UpdateNewMessage in either mode.
+// Bots can't read chat history, so a bot implementation should return empty client.Messages from GetChatHistory,
+// in which case TgScraper skips the history and relies on real-time updates only.
+// GetChat is called only if WithExpectedChatTitle is set.
 type TgClient interface {
 	GetChatHistory(req *client.GetChatHistoryRequest) (*client.Messages, error)
 	GetListener() *client.Listener
+	GetChat(req *client.GetChatRequest) (*client.Chat, error)
 }
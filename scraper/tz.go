@@ -2,15 +2,22 @@ package scraper
 
 import (
 	"fmt"
+	"sync"
 	"time"
+	_ "time/tzdata" // fallback for systems without tzdata, e.g. scratch or distroless images
 )
 
-var kyivLocation *time.Location
-
-func init() {
+// kyivLocation returns the Europe/Kyiv timezone, loaded on first use.
+// The embedded tzdata guarantees it's found even on systems without tzdata.
+var kyivLocation = sync.OnceValue(func() *time.Location {
 	loc, err := time.LoadLocation("Europe/Kyiv")
 	if err != nil {
-		panic(fmt.Errorf("unable to load Europe/Kyiv timezone: %w", err))
+		panic(fmt.Errorf("scraper: unable to load Europe/Kyiv timezone: %w", err))
 	}
-	kyivLocation = loc
+	return loc
+})
+
+// KyivLocation returns the Europe/Kyiv timezone the message times are parsed in.
+func KyivLocation() *time.Location {
+	return kyivLocation()
 }
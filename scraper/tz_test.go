@@ -0,0 +1,26 @@
+package scraper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mineroot/alert-data/scraper"
+)
+
+func TestKyivLocation(t *testing.T) {
+	kyiv := scraper.KyivLocation()
+	require.Equal(t, "Europe/Kyiv", kyiv.String())
+
+	// DST switches in 2024: 31 March 01:00 UTC and 27 October 01:00 UTC
+	for at, offset := range map[time.Time]int{
+		time.Date(2024, 3, 31, 0, 59, 59, 0, time.UTC):  2 * 60 * 60,
+		time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC):    3 * 60 * 60,
+		time.Date(2024, 10, 27, 0, 59, 59, 0, time.UTC): 3 * 60 * 60,
+		time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC):   2 * 60 * 60,
+	} {
+		_, got := at.In(kyiv).Zone()
+		require.Equal(t, offset, got, at)
+	}
+}
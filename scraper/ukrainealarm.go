@@ -0,0 +1,218 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+const ukraineAlarmBaseURL = "https://api.ukrainealarm.com"
+
+// UkraineAlarmScraper is a Source polling alert statuses from the ukrainealarm.com REST API.
+type UkraineAlarmScraper struct {
+	apiKey       string
+	baseURL      string
+	pollInterval time.Duration
+	httpClient   *http.Client
+	logger       *slog.Logger
+
+	once      sync.Once
+	alertData *AlertData
+	updates   chan Status
+}
+
+// NewUkraineAlarmScraper creates a UkraineAlarmScraper with the given API key and optional settings.
+func NewUkraineAlarmScraper(apiKey string, opts ...func(*UkraineAlarmScraper)) *UkraineAlarmScraper {
+	scraper := &UkraineAlarmScraper{
+		apiKey:       apiKey,
+		baseURL:      ukraineAlarmBaseURL,
+		pollInterval: 15 * time.Second,
+		httpClient:   http.DefaultClient,
+		logger:       slog.Default(),
+
+		once:      sync.Once{},
+		alertData: newAlertData(nil),
+		updates:   nil,
+	}
+	for _, o := range opts {
+		o(scraper)
+	}
+	return scraper
+}
+
+// WithUkraineAlarmBaseURL sets the base URL of the API.
+// Default is https://api.ukrainealarm.com.
+func WithUkraineAlarmBaseURL(baseURL string) func(*UkraineAlarmScraper) {
+	return func(s *UkraineAlarmScraper) {
+		s.baseURL = baseURL
+	}
+}
+
+// WithUkraineAlarmPollInterval sets the interval between API requests.
+// Default is 15 seconds.
+func WithUkraineAlarmPollInterval(interval time.Duration) func(*UkraineAlarmScraper) {
+	return func(s *UkraineAlarmScraper) {
+		s.pollInterval = interval
+	}
+}
+
+// WithUkraineAlarmHTTPClient sets the HTTP client used for API requests.
+// Default is http.DefaultClient.
+func WithUkraineAlarmHTTPClient(httpClient *http.Client) func(*UkraineAlarmScraper) {
+	return func(s *UkraineAlarmScraper) {
+		s.httpClient = httpClient
+	}
+}
+
+// WithUkraineAlarmLogger sets the logger, e.g. for failed API requests.
+// Default is slog.Default().
+func WithUkraineAlarmLogger(logger *slog.Logger) func(*UkraineAlarmScraper) {
+	return func(s *UkraineAlarmScraper) {
+		s.logger = logger
+	}
+}
+
+// Run starts polling the API until ctx is done.
+// Failed requests, e.g. server errors or timeouts, are logged and retried on the next poll.
+func (r *UkraineAlarmScraper) Run(ctx context.Context) error {
+	if r.httpClient == nil {
+		panic("scraper: use scraper.NewUkraineAlarmScraper() to create *UkraineAlarmScraper instance")
+	}
+	if ctx == nil {
+		panic("scraper: nil context")
+	}
+	var err error
+	r.once.Do(func() {
+		err = r.run(ctx)
+	})
+
+	if err != nil {
+		return fmt.Errorf("scraper: %w", err)
+	}
+	return nil
+}
+
+// AlertData returns current alert statuses.
+func (r *UkraineAlarmScraper) AlertData() *AlertData {
+	return r.alertData
+}
+
+// UpdatesChan returns a channel with status updates.
+func (r *UkraineAlarmScraper) UpdatesChan() <-chan Status {
+	if r.updates == nil {
+		r.updates = make(chan Status, 1)
+	}
+	return r.updates
+}
+
+func (r *UkraineAlarmScraper) run(ctx context.Context) error {
+	defer r.closeUpdates()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			r.logger.Warn("scraper: ukrainealarm.com poll failed", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ukraineAlarmRegion is a region with active alerts in the /api/v3/alerts response.
+type ukraineAlarmRegion struct {
+	RegionName   string    `json:"regionName"`
+	LastUpdate   time.Time `json:"lastUpdate"`
+	ActiveAlerts []struct {
+		Type string `json:"type"`
+	} `json:"activeAlerts"`
+}
+
+func (r *UkraineAlarmScraper) poll(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/api/v3/alerts", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", r.apiKey)
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected ukrainealarm.com response status: %s", res.Status)
+	}
+	var regions []ukraineAlarmRegion
+	if err = json.NewDecoder(res.Body).Decode(&regions); err != nil {
+		return fmt.Errorf("unable to decode ukrainealarm.com response: %w", err)
+	}
+
+	// the API lists regions with active alerts only
+	active := make(map[region.ID]time.Time, len(regions))
+	for _, alarmRegion := range regions {
+		id := region.ParseName(alarmRegion.RegionName)
+		if id == region.Invalid || !hasAirAlert(alarmRegion) {
+			continue
+		}
+		active[id] = alarmRegion.LastUpdate.In(kyivLocation())
+	}
+
+	now := time.Now().In(kyivLocation())
+	for _, current := range r.alertData.ToSlice() {
+		updatedAt, enabled := active[current.Region]
+		if enabled == current.Enabled() {
+			continue // unchanged, keep the previous UpdatedAt
+		}
+		level := LevelFull
+		if !enabled {
+			level = LevelNone
+			updatedAt = now // the API doesn't tell when the alert was disabled
+		}
+		status := Status{
+			Region:    current.Region,
+			Level:     level,
+			UpdatedAt: updatedAt,
+		}
+		if _, stored := r.alertData.set(&status); stored {
+			r.sendUpdate(ctx, status)
+		}
+	}
+	return nil
+}
+
+func hasAirAlert(alarmRegion ukraineAlarmRegion) bool {
+	for _, alert := range alarmRegion.ActiveAlerts {
+		if alert.Type == "AIR" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UkraineAlarmScraper) sendUpdate(ctx context.Context, status Status) {
+	if r.updates == nil {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case r.updates <- status:
+	}
+}
+
+func (r *UkraineAlarmScraper) closeUpdates() {
+	if r.updates != nil {
+		close(r.updates)
+	}
+}
@@ -0,0 +1,211 @@
+package scraper_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mineroot/alert-data/scraper"
+	"github.com/mineroot/alert-data/scraper/region"
+)
+
+const ukraineAlarmResponse = `[
+  {
+    "regionId": "9999",
+    "regionType": "State",
+    "regionName": "Автономна Республіка Крим",
+    "regionEngName": "Autonomous Republic of Crimea",
+    "lastUpdate": "2022-12-10T22:22:00Z",
+    "activeAlerts": [{"regionId": "9999", "regionType": "State", "type": "AIR", "lastUpdate": "2022-12-10T22:22:00Z"}]
+  },
+  {
+    "regionId": "16",
+    "regionType": "State",
+    "regionName": "Луганська область",
+    "regionEngName": "Luhansk oblast",
+    "lastUpdate": "2022-04-04T16:45:00Z",
+    "activeAlerts": [{"regionId": "16", "regionType": "State", "type": "AIR", "lastUpdate": "2022-04-04T16:45:00Z"}]
+  },
+  {
+    "regionId": "18",
+    "regionType": "State",
+    "regionName": "Одеська область",
+    "regionEngName": "Odesa oblast",
+    "lastUpdate": "2024-08-20T23:15:19Z",
+    "activeAlerts": [{"regionId": "18", "regionType": "State", "type": "AIR", "lastUpdate": "2024-08-20T23:15:19Z"}]
+  },
+  {
+    "regionId": "31",
+    "regionType": "City",
+    "regionName": "м. Київ",
+    "regionEngName": "Kyiv City",
+    "lastUpdate": "2024-08-22T05:39:00Z",
+    "activeAlerts": [{"regionId": "31", "regionType": "City", "type": "ARTILLERY", "lastUpdate": "2024-08-22T05:39:00Z"}]
+  }
+]`
+
+func TestUkraineAlarmScraper(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/alerts" || r.Header.Get("Authorization") != "api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(ukraineAlarmResponse))
+	}))
+	defer server.Close()
+
+	var source scraper.Source = scraper.NewUkraineAlarmScraper(
+		"api-key",
+		scraper.WithUkraineAlarmBaseURL(server.URL),
+		scraper.WithUkraineAlarmPollInterval(time.Hour),
+	)
+	updates := source.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return source.Run(ctx)
+	})
+
+	status := <-updates
+	require.Equal(t, scraper.Status{
+		Region:    region.Odesa,
+		Level:     scraper.LevelFull,
+		UpdatedAt: strToDate("2024-08-21 02:15:19"),
+	}, status)
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+
+	// assert AlertData is populated
+	status, _ = source.AlertData().GetByRegion(region.Odesa)
+	require.True(t, status.Enabled())
+	// not an air alert
+	status, _ = source.AlertData().GetByRegion(region.KyivCity)
+	require.False(t, status.Enabled())
+	// regions absent from the response are disabled
+	status, _ = source.AlertData().GetByRegion(region.Lviv)
+	require.False(t, status.Enabled())
+	status, _ = source.AlertData().GetByRegion(region.Crimea)
+	require.True(t, status.Enabled())
+}
+
+func TestUkraineAlarmScraper_TransientErrors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	odesa := ukraineAlarmResponse[strings.Index(ukraineAlarmResponse, `  {
+    "regionId": "18"`):strings.Index(ukraineAlarmResponse, `  {
+    "regionId": "31"`)]
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			_, _ = w.Write([]byte(ukraineAlarmResponse))
+		case 3:
+			_, _ = w.Write([]byte("[{"))
+		default: // the alert in Odesa oblast is cleared
+			_, _ = w.Write([]byte(strings.Replace(ukraineAlarmResponse, odesa, "", 1)))
+		}
+	}))
+	defer server.Close()
+
+	source := scraper.NewUkraineAlarmScraper(
+		"api-key",
+		scraper.WithUkraineAlarmBaseURL(server.URL),
+		scraper.WithUkraineAlarmPollInterval(time.Millisecond),
+		scraper.WithUkraineAlarmLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	updates := source.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return source.Run(ctx)
+	})
+
+	status := <-updates
+	require.Equal(t, region.Odesa, status.Region, "polling must survive the server error")
+	require.True(t, status.Enabled())
+	cleared := <-updates
+	require.Equal(t, region.Odesa, cleared.Region, "polling must survive the malformed response")
+	require.False(t, cleared.Enabled())
+
+	require.Eventually(t, func() bool {
+		return calls.Load() > 10
+	}, time.Second, time.Millisecond)
+	select {
+	case status = <-updates:
+		require.Fail(t, "unexpected update of unchanged region", status)
+	default:
+	}
+	status, _ = source.AlertData().GetByRegion(region.Odesa)
+	require.Equal(t, cleared.UpdatedAt, status.UpdatedAt, "UpdatedAt of unchanged region must be kept")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}
+
+func TestUkraineAlarmScraper_StaleAlert(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	odesa := ukraineAlarmResponse[strings.Index(ukraineAlarmResponse, `  {
+    "regionId": "18"`):strings.Index(ukraineAlarmResponse, `  {
+    "regionId": "31"`)]
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 2: // the alert in Odesa oblast is cleared
+			_, _ = w.Write([]byte(strings.Replace(ukraineAlarmResponse, odesa, "", 1)))
+		default: // the alert is listed again, but it's older than the clearance
+			_, _ = w.Write([]byte(ukraineAlarmResponse))
+		}
+	}))
+	defer server.Close()
+
+	source := scraper.NewUkraineAlarmScraper(
+		"api-key",
+		scraper.WithUkraineAlarmBaseURL(server.URL),
+		scraper.WithUkraineAlarmPollInterval(time.Millisecond),
+	)
+	updates := source.UpdatesChan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return source.Run(ctx)
+	})
+
+	status := <-updates
+	require.True(t, status.Enabled())
+	cleared := <-updates
+	require.False(t, cleared.Enabled())
+
+	require.Eventually(t, func() bool {
+		return calls.Load() > 10
+	}, time.Second, time.Millisecond)
+	select {
+	case status = <-updates:
+		require.Fail(t, "unexpected update of rejected status", status)
+	default:
+	}
+	status, _ = source.AlertData().GetByRegion(region.Odesa)
+	require.Equal(t, cleared, status, "the stale alert must not be stored")
+
+	cancel()
+	require.ErrorIs(t, g.Wait(), context.Canceled)
+}